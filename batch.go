@@ -0,0 +1,196 @@
+package surreal
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// batchConcurrency caps the calls in flight at once when a batch is sent over a connection that cannot pipeline them.
+const batchConcurrency = 16
+
+// pendingCall is a call of a batch. resolve is invoked exactly once, with the call's result or the reason it failed.
+type pendingCall struct {
+	method  string
+	params  []any
+	resolve func(result []byte, err error)
+}
+
+// pipeliner is implemented by connections able to write many calls before waiting for the first response.
+type pipeliner interface {
+	pipeline(ctx context.Context, calls []*pendingCall)
+}
+
+// Future is the outcome of a call added to a Batch. It is resolved once the call's response has arrived and has been
+// decoded into the destination given when the call was added.
+type Future struct {
+	done chan struct{}
+	err  error
+}
+
+// Done returns a channel closed once the future is resolved.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the future to be resolved and returns the call's error, if any. The batch must have been sent.
+func (f *Future) Wait() error {
+	<-f.done
+	return f.err
+}
+
+// Batch collects calls to be sent together. Over websocket connections the calls are written back to back without
+// waiting for responses in between, so that many of them cost about a single round trip. Calls are added with the
+// same methods as on DB, each returning a Future, and sent with Send or Wait.
+//
+// A batch is not safe for concurrent use, and calls must not be added once it has been sent.
+type Batch struct {
+	db      *DB
+	calls   []*pendingCall
+	futures []*Future
+	once    sync.Once
+}
+
+// Batch starts an empty batch of calls.
+func (db *DB) Batch() *Batch {
+	return &Batch{db: db}
+}
+
+// Len returns the number of calls in the batch.
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+func (b *Batch) add(method string, params []any, decode func(raw []byte) error) *Future {
+	future := &Future{done: make(chan struct{})}
+	b.calls = append(b.calls, &pendingCall{
+		method: method,
+		params: params,
+		resolve: func(result []byte, err error) {
+			if err == nil && decode != nil {
+				err = decode(result)
+			}
+			future.err = err
+			close(future.done)
+		},
+	})
+	b.futures = append(b.futures, future)
+	return future
+}
+
+// scanInto decodes a result into the first of destination, if any.
+func (b *Batch) scanInto(destination []any) func(raw []byte) error {
+	if len(destination) == 0 {
+		return nil
+	}
+	return func(raw []byte) error {
+		return autoScan(b.db.codec, raw, destination[0])
+	}
+}
+
+// Query adds a query, see DB.Query.
+func (b *Batch) Query(query string, vars Map, scanDestinations ...any) *Future {
+	return b.add("query", []any{query, vars}, func(raw []byte) error {
+		rawQueryResult, err := b.db.decodeQueryResult(raw)
+		if err != nil {
+			return err
+		}
+		if err := b.db.newQueryResults(rawQueryResult).Err(); err != nil {
+			return err
+		}
+		return b.db.scanQueryResult(rawQueryResult, scanDestinations)
+	})
+}
+
+// Select adds a select, see DB.Select.
+func (b *Batch) Select(id any, destination any) *Future {
+	return b.add("select", []any{b.db.thing(id)}, func(raw []byte) error {
+		if b.db.codec.shape(raw) == shapeNull {
+			return ErrNotFound
+		}
+		return autoScan(b.db.codec, raw, destination)
+	})
+}
+
+// Create adds a create, see DB.Create.
+func (b *Batch) Create(what any, data any, destination ...any) *Future {
+	return b.add("create", []any{b.db.thing(what), data}, b.scanInto(destination))
+}
+
+// Insert adds an insert, see DB.Insert.
+func (b *Batch) Insert(table string, data any, destination ...any) *Future {
+	return b.add("insert", []any{table, data}, b.scanInto(destination))
+}
+
+// Relate adds a relate, see DB.Relate.
+func (b *Batch) Relate(from any, thing string, to any, data any, destination ...any) *Future {
+	return b.add("relate", []any{b.db.thing(from), thing, b.db.thing(to), data}, b.scanInto(destination))
+}
+
+// Update adds an update, see DB.Update.
+func (b *Batch) Update(id any, data any, destination ...any) *Future {
+	return b.add("update", []any{b.db.thing(id), data}, b.scanInto(destination))
+}
+
+// Upsert adds an upsert, see DB.Upsert.
+func (b *Batch) Upsert(id any, data any, destination ...any) *Future {
+	return b.add("upsert", []any{b.db.thing(id), data}, b.scanInto(destination))
+}
+
+// Merge adds a merge, see DB.Merge.
+func (b *Batch) Merge(id any, data any, destination ...any) *Future {
+	return b.add("merge", []any{b.db.thing(id), data}, b.scanInto(destination))
+}
+
+// Patch adds a patch, see DB.Patch.
+func (b *Batch) Patch(id any, diff []Diff, destination ...any) *Future {
+	return b.add("patch", []any{b.db.thing(id), diff}, b.scanInto(destination))
+}
+
+// Delete adds a delete, see DB.Delete.
+func (b *Batch) Delete(id any, destination ...any) *Future {
+	return b.add("delete", []any{b.db.thing(id)}, b.scanInto(destination))
+}
+
+// Send starts sending the batch and returns without waiting for the responses. Sending a batch more than once has no
+// effect.
+func (b *Batch) Send() {
+	b.SendContext(context.Background())
+}
+
+// SendContext is like Send, but calls still waiting for their responses fail with ctx.Err() once ctx is done.
+func (b *Batch) SendContext(ctx context.Context) {
+	b.once.Do(func() {
+		go b.send(ctx)
+	})
+}
+
+func (b *Batch) send(ctx context.Context) {
+	if conn, ok := b.db.conn.(pipeliner); ok {
+		conn.pipeline(ctx, b.calls)
+		return
+	}
+
+	semaphore := make(chan struct{}, batchConcurrency)
+	for _, call := range b.calls {
+		semaphore <- struct{}{}
+		go func() {
+			defer func() { <-semaphore }()
+			call.resolve(b.db.conn.Send(ctx, call.method, call.params))
+		}()
+	}
+}
+
+// Wait sends the batch, unless it has been sent already, and waits for all of its calls. It returns the errors of the
+// failed calls, joined, or nil if all of them succeeded.
+func (b *Batch) Wait() error {
+	b.Send()
+
+	var errs []error
+	for _, future := range b.futures {
+		if err := future.Wait(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
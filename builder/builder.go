@@ -0,0 +1,157 @@
+// Package builder builds SurrealQL statements. Values are never written into the query text, they are bound as
+// $p0, $p1, ... parameters instead, so that statements are safe to build from untrusted input.
+//
+//	query, vars, err := builder.Select("article").
+//		Fields("title", "author.name").
+//		Where(builder.Eq("published", true)).
+//		OrderByDesc("createdAt").
+//		Limit(10).
+//		Build()
+//
+// Statements act on tables, given as a string or a surreal.Table, or on single records, given as a surreal.RecordID.
+// They implement surreal.Statement and can be run with DB.Execute. Misuse, like a statement without targets or an
+// Expr with the wrong number of arguments, is reported as an error by Build.
+package builder
+
+import (
+	"fmt"
+	"github.com/terawatthour/surreal-go"
+	"strconv"
+	"strings"
+)
+
+// params collects the values bound while a statement is built, along with the misuse of the builder found meanwhile.
+type params struct {
+	vars surreal.Map
+	err  error
+}
+
+func newParams() *params {
+	return &params{vars: surreal.Map{}}
+}
+
+// bind binds value to a fresh parameter and returns its name, including the $.
+func (p *params) bind(value any) string {
+	name := "p" + strconv.Itoa(len(p.vars))
+	p.vars[name] = value
+	return "$" + name
+}
+
+// fail records misuse of the builder, keeping the first one found.
+func (p *params) fail(format string, args ...any) {
+	if p.err == nil {
+		p.err = fmt.Errorf("builder: "+format, args...)
+	}
+}
+
+// result returns the outcome of building query.
+func (p *params) result(query string) (string, surreal.Map, error) {
+	if p.err != nil {
+		return "", nil, p.err
+	}
+	return query, p.vars, nil
+}
+
+// target renders what a statement acts on. Strings and surreal.Table name tables, a surreal.RecordID names a single
+// record.
+func target(p *params, what any) string {
+	switch what := what.(type) {
+	case string:
+		return surreal.EscapeIdent(what)
+	case surreal.Table:
+		return surreal.EscapeIdent(string(what))
+	case surreal.RecordID:
+		return "type::thing(" + p.bind(what.Table) + ", " + p.bind(what.ID) + ")"
+	case *surreal.RecordID:
+		return target(p, *what)
+	default:
+		p.fail("unsupported target %T, expected a table name or a surreal.RecordID", what)
+		return ""
+	}
+}
+
+func targets(p *params, what []any) string {
+	if len(what) == 0 {
+		p.fail("statement has no targets, expected a table name or a surreal.RecordID")
+	}
+	rendered := make([]string, len(what))
+	for i, w := range what {
+		rendered[i] = target(p, w)
+	}
+	return strings.Join(rendered, ", ")
+}
+
+// field escapes a field path like `author.name`, segment by segment. `*` is left as it is.
+func field(path string) string {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		if segment != "*" {
+			segments[i] = surreal.EscapeIdent(segment)
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+func fields(paths []string) string {
+	rendered := make([]string, len(paths))
+	for i, path := range paths {
+		rendered[i] = field(path)
+	}
+	return strings.Join(rendered, ", ")
+}
+
+// assignment is a single `field = value` of a SET clause.
+type assignment struct {
+	field string
+	value any
+}
+
+// data is the part of CREATE, UPDATE, UPSERT and RELATE statements describing the record contents.
+type data struct {
+	keyword string
+	value   any
+	set     []assignment
+}
+
+func (d *data) build(p *params) string {
+	switch {
+	case d.keyword != "":
+		return " " + d.keyword + " " + p.bind(d.value)
+	case len(d.set) != 0:
+		rendered := make([]string, len(d.set))
+		for i, a := range d.set {
+			rendered[i] = field(a.field) + " = " + p.bind(a.value)
+		}
+		return " SET " + strings.Join(rendered, ", ")
+	default:
+		return ""
+	}
+}
+
+// Return values accepted by the Return methods, next to field names.
+const (
+	ReturnNone   = "NONE"
+	ReturnBefore = "BEFORE"
+	ReturnAfter  = "AFTER"
+	ReturnDiff   = "DIFF"
+)
+
+func returning(what []string) string {
+	if len(what) == 0 {
+		return ""
+	}
+	if len(what) == 1 {
+		switch strings.ToUpper(what[0]) {
+		case ReturnNone, ReturnBefore, ReturnAfter, ReturnDiff:
+			return " RETURN " + strings.ToUpper(what[0])
+		}
+	}
+	return " RETURN " + fields(what)
+}
+
+func where(p *params, conditions []Condition) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + And(conditions...).build(p)
+}
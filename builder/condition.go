@@ -0,0 +1,158 @@
+package builder
+
+import "strings"
+
+// Condition is a boolean expression used in WHERE clauses.
+type Condition interface {
+	build(p *params) string
+}
+
+type comparison struct {
+	field    string
+	operator string
+	value    any
+}
+
+func (c comparison) build(p *params) string {
+	return field(c.field) + " " + c.operator + " " + p.bind(c.value)
+}
+
+// Eq matches records whose field equals value.
+func Eq(field string, value any) Condition {
+	return comparison{field, "=", value}
+}
+
+// Neq matches records whose field does not equal value.
+func Neq(field string, value any) Condition {
+	return comparison{field, "!=", value}
+}
+
+// Gt matches records whose field is greater than value.
+func Gt(field string, value any) Condition {
+	return comparison{field, ">", value}
+}
+
+// Gte matches records whose field is greater than or equal to value.
+func Gte(field string, value any) Condition {
+	return comparison{field, ">=", value}
+}
+
+// Lt matches records whose field is less than value.
+func Lt(field string, value any) Condition {
+	return comparison{field, "<", value}
+}
+
+// Lte matches records whose field is less than or equal to value.
+func Lte(field string, value any) Condition {
+	return comparison{field, "<=", value}
+}
+
+// Contains matches records whose field, an array or a string, contains value.
+func Contains(field string, value any) Condition {
+	return comparison{field, "CONTAINS", value}
+}
+
+// Inside matches records whose field is one of values, an array.
+func Inside(field string, values any) Condition {
+	return comparison{field, "INSIDE", values}
+}
+
+type junction struct {
+	operator   string
+	conditions []Condition
+}
+
+func (j junction) build(p *params) string {
+	if len(j.conditions) == 0 {
+		p.fail("%s of no conditions", j.operator)
+		return ""
+	}
+	if len(j.conditions) == 1 {
+		return j.conditions[0].build(p)
+	}
+
+	rendered := make([]string, len(j.conditions))
+	for i, c := range j.conditions {
+		rendered[i] = "(" + c.build(p) + ")"
+	}
+	return strings.Join(rendered, " "+j.operator+" ")
+}
+
+// And matches records matching all of conditions.
+func And(conditions ...Condition) Condition {
+	return junction{"AND", conditions}
+}
+
+// Or matches records matching any of conditions.
+func Or(conditions ...Condition) Condition {
+	return junction{"OR", conditions}
+}
+
+type negation struct {
+	condition Condition
+}
+
+func (n negation) build(p *params) string {
+	return "!(" + n.condition.build(p) + ")"
+}
+
+// Not matches records not matching condition.
+func Not(condition Condition) Condition {
+	return negation{condition}
+}
+
+type expression struct {
+	sql  string
+	args []any
+}
+
+func (e expression) build(p *params) string {
+	var b strings.Builder
+	arg := 0
+
+	for i := 0; i < len(e.sql); i++ {
+		c := e.sql[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(e.sql) && e.sql[end] != c {
+				if e.sql[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end, len(e.sql)-1)
+			b.WriteString(e.sql[i : end+1])
+			i = end
+		case c == '?' && !isOperator(e.sql, i):
+			if arg >= len(e.args) {
+				p.fail("expression %q has more placeholders than the %d arguments given", e.sql, len(e.args))
+				return ""
+			}
+			b.WriteString(p.bind(e.args[arg]))
+			arg++
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	if arg != len(e.args) {
+		p.fail("expression %q has %d placeholders, got %d arguments", e.sql, arg, len(e.args))
+	}
+
+	return b.String()
+}
+
+// isOperator tells whether the ? at i belongs to one of the ??, ?:, ?= or ?~ operators rather than being a placeholder.
+func isOperator(sql string, i int) bool {
+	if i > 0 && sql[i-1] == '?' {
+		return true
+	}
+	return i+1 < len(sql) && strings.IndexByte("?:=~", sql[i+1]) >= 0
+}
+
+// Expr is a condition written in SurrealQL, with a ? placeholder standing in for each of args, e.g.
+// Expr("count(tags) > ? OR featured = ?", 3, true). The ??, ?:, ?= and ?~ operators are left alone.
+func Expr(sql string, args ...any) Condition {
+	return expression{sql, args}
+}
@@ -0,0 +1,39 @@
+package builder
+
+import "github.com/terawatthour/surreal-go"
+
+// Within matches records whose field, a geometry, lies inside area, e.g. a surreal.Polygon.
+func Within(field string, area surreal.Geometry) Condition {
+	return comparison{field, "INSIDE", area}
+}
+
+// Outside matches records whose field, a geometry, lies outside area.
+func Outside(field string, area surreal.Geometry) Condition {
+	return comparison{field, "OUTSIDE", area}
+}
+
+// Intersects matches records whose field, a geometry, intersects with geometry.
+func Intersects(field string, geometry surreal.Geometry) Condition {
+	return comparison{field, "INTERSECTS", geometry}
+}
+
+type distance struct {
+	field    string
+	from     surreal.Point
+	operator string
+	meters   float64
+}
+
+func (d distance) build(p *params) string {
+	return "geo::distance(" + field(d.field) + ", " + p.bind(d.from) + ") " + d.operator + " " + p.bind(d.meters)
+}
+
+// NearerThan matches records whose field, a point, is less than meters away from from, as told by geo::distance.
+func NearerThan(field string, from surreal.Point, meters float64) Condition {
+	return distance{field, from, "<", meters}
+}
+
+// FartherThan matches records whose field, a point, is more than meters away from from, as told by geo::distance.
+func FartherThan(field string, from surreal.Point, meters float64) Condition {
+	return distance{field, from, ">", meters}
+}
@@ -0,0 +1,189 @@
+package builder
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"strings"
+)
+
+// CreateStatement builds a CREATE statement.
+type CreateStatement struct {
+	what    []any
+	data    data
+	returns []string
+}
+
+// Create starts a CREATE statement creating a record in a table, or with a given surreal.RecordID.
+func Create(what ...any) *CreateStatement {
+	return &CreateStatement{what: what}
+}
+
+// Content sets the contents of the created record.
+func (s *CreateStatement) Content(content any) *CreateStatement {
+	s.data = data{keyword: "CONTENT", value: content}
+	return s
+}
+
+// Set sets a single field of the created record. Repeated calls set further fields.
+func (s *CreateStatement) Set(field string, value any) *CreateStatement {
+	s.data.keyword = ""
+	s.data.set = append(s.data.set, assignment{field, value})
+	return s
+}
+
+// Return selects what the statement returns, either one of ReturnNone, ReturnBefore, ReturnAfter or ReturnDiff, or
+// a list of fields.
+func (s *CreateStatement) Return(what ...string) *CreateStatement {
+	s.returns = what
+	return s
+}
+
+// Build implements surreal.Statement.
+func (s *CreateStatement) Build() (string, surreal.Map, error) {
+	p := newParams()
+	query := "CREATE " + targets(p, s.what) + s.data.build(p) + returning(s.returns)
+	return p.result(query)
+}
+
+// UpdateStatement builds an UPDATE or UPSERT statement.
+type UpdateStatement struct {
+	keyword    string
+	what       []any
+	data       data
+	conditions []Condition
+	returns    []string
+}
+
+// Update starts an UPDATE statement changing all records of a table, or a single record.
+func Update(what ...any) *UpdateStatement {
+	return &UpdateStatement{keyword: "UPDATE", what: what}
+}
+
+// Upsert starts an UPSERT statement, which is like UPDATE but creates records that do not exist yet.
+func Upsert(what ...any) *UpdateStatement {
+	return &UpdateStatement{keyword: "UPSERT", what: what}
+}
+
+// Content replaces the contents of the records.
+func (s *UpdateStatement) Content(content any) *UpdateStatement {
+	s.data = data{keyword: "CONTENT", value: content}
+	return s
+}
+
+// Merge merges fields into the records.
+func (s *UpdateStatement) Merge(content any) *UpdateStatement {
+	s.data = data{keyword: "MERGE", value: content}
+	return s
+}
+
+// Patch applies JSON Patch operations to the records.
+func (s *UpdateStatement) Patch(diff []surreal.Diff) *UpdateStatement {
+	s.data = data{keyword: "PATCH", value: diff}
+	return s
+}
+
+// Set sets a single field of the records. Repeated calls set further fields.
+func (s *UpdateStatement) Set(field string, value any) *UpdateStatement {
+	s.data.keyword = ""
+	s.data.set = append(s.data.set, assignment{field, value})
+	return s
+}
+
+// Where restricts the statement to matching records. Conditions of repeated calls are combined with AND.
+func (s *UpdateStatement) Where(conditions ...Condition) *UpdateStatement {
+	s.conditions = append(s.conditions, conditions...)
+	return s
+}
+
+// Return selects what the statement returns, either one of ReturnNone, ReturnBefore, ReturnAfter or ReturnDiff, or
+// a list of fields.
+func (s *UpdateStatement) Return(what ...string) *UpdateStatement {
+	s.returns = what
+	return s
+}
+
+// Build implements surreal.Statement.
+func (s *UpdateStatement) Build() (string, surreal.Map, error) {
+	p := newParams()
+	query := s.keyword + " " + targets(p, s.what) + s.data.build(p) + where(p, s.conditions) + returning(s.returns)
+	return p.result(query)
+}
+
+// DeleteStatement builds a DELETE statement.
+type DeleteStatement struct {
+	what       []any
+	conditions []Condition
+	returns    []string
+}
+
+// Delete starts a DELETE statement deleting all records of a table, or a single record.
+func Delete(what ...any) *DeleteStatement {
+	return &DeleteStatement{what: what}
+}
+
+// Where restricts the statement to matching records. Conditions of repeated calls are combined with AND.
+func (s *DeleteStatement) Where(conditions ...Condition) *DeleteStatement {
+	s.conditions = append(s.conditions, conditions...)
+	return s
+}
+
+// Return selects what the statement returns, either one of ReturnNone, ReturnBefore, ReturnAfter or ReturnDiff, or
+// a list of fields.
+func (s *DeleteStatement) Return(what ...string) *DeleteStatement {
+	s.returns = what
+	return s
+}
+
+// Build implements surreal.Statement.
+func (s *DeleteStatement) Build() (string, surreal.Map, error) {
+	p := newParams()
+	query := "DELETE " + targets(p, s.what) + where(p, s.conditions) + returning(s.returns)
+	return p.result(query)
+}
+
+// RelateStatement builds a RELATE statement.
+type RelateStatement struct {
+	from    surreal.RecordID
+	edge    string
+	to      surreal.RecordID
+	data    data
+	returns []string
+}
+
+// Relate starts a RELATE statement creating an edge record in the edge table, pointing from one record to another.
+func Relate(from surreal.RecordID, edge string, to surreal.RecordID) *RelateStatement {
+	return &RelateStatement{from: from, edge: edge, to: to}
+}
+
+// Content sets the contents of the edge record.
+func (s *RelateStatement) Content(content any) *RelateStatement {
+	s.data = data{keyword: "CONTENT", value: content}
+	return s
+}
+
+// Set sets a single field of the edge record. Repeated calls set further fields.
+func (s *RelateStatement) Set(field string, value any) *RelateStatement {
+	s.data.keyword = ""
+	s.data.set = append(s.data.set, assignment{field, value})
+	return s
+}
+
+// Return selects what the statement returns, either one of ReturnNone, ReturnBefore, ReturnAfter or ReturnDiff, or
+// a list of fields.
+func (s *RelateStatement) Return(what ...string) *RelateStatement {
+	s.returns = what
+	return s
+}
+
+// Build implements surreal.Statement.
+func (s *RelateStatement) Build() (string, surreal.Map, error) {
+	p := newParams()
+
+	var b strings.Builder
+	b.WriteString("RELATE (" + target(p, s.from) + ")->")
+	b.WriteString(surreal.EscapeIdent(s.edge))
+	b.WriteString("->(" + target(p, s.to) + ")")
+	b.WriteString(s.data.build(p))
+	b.WriteString(returning(s.returns))
+
+	return p.result(b.String())
+}
@@ -0,0 +1,149 @@
+package builder
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"strings"
+)
+
+type order struct {
+	field string
+	desc  bool
+}
+
+// SelectStatement builds a SELECT statement.
+type SelectStatement struct {
+	what       []any
+	fields     []string
+	value      string
+	omit       []string
+	conditions []Condition
+	groupBy    []string
+	orderBy    []order
+	limit      *int
+	start      *int
+	fetch      []string
+	only       bool
+}
+
+// Select starts a SELECT statement reading from tables or records. All fields are selected unless Fields or Value is
+// given.
+func Select(what ...any) *SelectStatement {
+	return &SelectStatement{what: what}
+}
+
+// Fields selects the given fields, e.g. "title" or "author.name", instead of all of them.
+func (s *SelectStatement) Fields(fields ...string) *SelectStatement {
+	s.fields = append(s.fields, fields...)
+	return s
+}
+
+// Value selects the bare value of a single field instead of records.
+func (s *SelectStatement) Value(field string) *SelectStatement {
+	s.value = field
+	return s
+}
+
+// Omit leaves fields out of the selected records.
+func (s *SelectStatement) Omit(fields ...string) *SelectStatement {
+	s.omit = append(s.omit, fields...)
+	return s
+}
+
+// Only selects a single record rather than a list of them.
+func (s *SelectStatement) Only() *SelectStatement {
+	s.only = true
+	return s
+}
+
+// Where filters the selected records. Conditions of repeated calls are combined with AND.
+func (s *SelectStatement) Where(conditions ...Condition) *SelectStatement {
+	s.conditions = append(s.conditions, conditions...)
+	return s
+}
+
+// GroupBy groups the selected records by fields.
+func (s *SelectStatement) GroupBy(fields ...string) *SelectStatement {
+	s.groupBy = append(s.groupBy, fields...)
+	return s
+}
+
+// OrderBy sorts the selected records by field in ascending order. Repeated calls add further sort keys.
+func (s *SelectStatement) OrderBy(field string) *SelectStatement {
+	s.orderBy = append(s.orderBy, order{field: field})
+	return s
+}
+
+// OrderByDesc sorts the selected records by field in descending order. Repeated calls add further sort keys.
+func (s *SelectStatement) OrderByDesc(field string) *SelectStatement {
+	s.orderBy = append(s.orderBy, order{field: field, desc: true})
+	return s
+}
+
+// Limit caps the number of selected records.
+func (s *SelectStatement) Limit(limit int) *SelectStatement {
+	s.limit = &limit
+	return s
+}
+
+// Start skips the first start selected records.
+func (s *SelectStatement) Start(start int) *SelectStatement {
+	s.start = &start
+	return s
+}
+
+// Fetch replaces the record ids in fields with the records they point to.
+func (s *SelectStatement) Fetch(fields ...string) *SelectStatement {
+	s.fetch = append(s.fetch, fields...)
+	return s
+}
+
+// Build implements surreal.Statement.
+func (s *SelectStatement) Build() (string, surreal.Map, error) {
+	p := newParams()
+
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	switch {
+	case s.value != "":
+		b.WriteString("VALUE " + field(s.value))
+	case len(s.fields) != 0:
+		b.WriteString(fields(s.fields))
+	default:
+		b.WriteString("*")
+	}
+	if len(s.omit) != 0 {
+		b.WriteString(" OMIT " + fields(s.omit))
+	}
+
+	b.WriteString(" FROM ")
+	if s.only {
+		b.WriteString("ONLY ")
+	}
+	b.WriteString(targets(p, s.what))
+	b.WriteString(where(p, s.conditions))
+
+	if len(s.groupBy) != 0 {
+		b.WriteString(" GROUP BY " + fields(s.groupBy))
+	}
+	if len(s.orderBy) != 0 {
+		rendered := make([]string, len(s.orderBy))
+		for i, o := range s.orderBy {
+			rendered[i] = field(o.field)
+			if o.desc {
+				rendered[i] += " DESC"
+			}
+		}
+		b.WriteString(" ORDER BY " + strings.Join(rendered, ", "))
+	}
+	if s.limit != nil {
+		b.WriteString(" LIMIT " + p.bind(*s.limit))
+	}
+	if s.start != nil {
+		b.WriteString(" START " + p.bind(*s.start))
+	}
+	if len(s.fetch) != 0 {
+		b.WriteString(" FETCH " + fields(s.fetch))
+	}
+
+	return p.result(b.String())
+}
@@ -0,0 +1,101 @@
+package surreal
+
+import (
+	"fmt"
+	"github.com/fxamacker/cbor/v2"
+	"reflect"
+)
+
+// Custom CBOR tags used by SurrealDB, see https://surrealdb.com/docs/surrealdb/integration/cbor
+const (
+	tagSpecDatetime         = 0
+	tagNone                 = 6
+	tagTable                = 7
+	tagRecordID             = 8
+	tagStringUUID           = 9
+	tagStringDecimal        = 10
+	tagCustomDatetime       = 12
+	tagStringDuration       = 13
+	tagCustomDuration       = 14
+	tagSpecUUID             = 37
+	tagRange                = 49
+	tagBoundIncluded        = 50
+	tagBoundExcluded        = 51
+	tagGeometryPoint        = 88
+	tagGeometryLine         = 89
+	tagGeometryPolygon      = 90
+	tagGeometryMultiPoint   = 91
+	tagGeometryMultiLine    = 92
+	tagGeometryMultiPolygon = 93
+	tagGeometryCollection   = 94
+)
+
+var (
+	cborEncMode cbor.EncMode
+	cborDecMode cbor.DecMode
+)
+
+func init() {
+	tags := cbor.NewTagSet()
+	register := func(value any, number uint64) {
+		options := cbor.TagOptions{EncTag: cbor.EncTagRequired, DecTag: cbor.DecTagRequired}
+		if err := tags.Add(options, reflect.TypeOf(value), number); err != nil {
+			panic(fmt.Sprintf("failed to register cbor tag %d: %s", number, err))
+		}
+	}
+
+	register(None, tagNone)
+	register(Table(""), tagTable)
+	register(RecordID{}, tagRecordID)
+	register(Decimal(""), tagStringDecimal)
+	register(Datetime{}, tagCustomDatetime)
+	register(Duration(0), tagCustomDuration)
+	register(UUID{}, tagSpecUUID)
+	register(Range{}, tagRange)
+	register(Point{}, tagGeometryPoint)
+	register(LineString{}, tagGeometryLine)
+	register(Polygon{}, tagGeometryPolygon)
+	register(MultiPoint{}, tagGeometryMultiPoint)
+	register(MultiLineString{}, tagGeometryMultiLine)
+	register(MultiPolygon{}, tagGeometryMultiPolygon)
+	register(GeometryCollection{}, tagGeometryCollection)
+
+	var err error
+	cborEncMode, err = cbor.EncOptions{
+		// time.Time values are sent as RFC 3339 strings under the standard datetime tag, which SurrealDB understands
+		Time:    cbor.TimeRFC3339Nano,
+		TimeTag: cbor.EncTagRequired,
+	}.EncModeWithTags(tags)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create cbor encoding mode: %s", err))
+	}
+
+	cborDecMode, err = cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]any(nil)),
+	}.DecModeWithTags(tags)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create cbor decoding mode: %s", err))
+	}
+}
+
+// marshalTagged encodes content under the given tag number.
+func marshalTagged(number uint64, content any) ([]byte, error) {
+	return cborEncMode.Marshal(cbor.Tag{Number: number, Content: content})
+}
+
+// unmarshalTagged decodes a tagged item into content, reporting the tag number it was found under. Fails if the tag
+// number is not one of accepted.
+func unmarshalTagged(data []byte, content any, accepted ...uint64) (uint64, error) {
+	var raw cbor.RawTag
+	if err := cborDecMode.Unmarshal(data, &raw); err != nil {
+		return 0, err
+	}
+
+	for _, number := range accepted {
+		if raw.Number == number {
+			return raw.Number, cborDecMode.Unmarshal(raw.Content, content)
+		}
+	}
+
+	return 0, fmt.Errorf("unexpected cbor tag %d", raw.Number)
+}
@@ -0,0 +1,51 @@
+// Command surreal-gen generates Go types mirroring the schema of a SurrealDB database, see package surrealgen.
+//
+//	surreal-gen -url ws://localhost:8000/rpc -ns app -db app -user root -pass root -package models -o models/schema.go
+//
+// It signs in as a root user unless -ns or -db level credentials are asked for with -level, and inspects every table
+// unless some are listed with -tables.
+package main
+
+import (
+	"flag"
+	"github.com/terawatthour/surreal-go/internal/cli"
+	"github.com/terawatthour/surreal-go/surrealgen"
+	"os"
+	"strings"
+)
+
+func main() {
+	connection := cli.RegisterConnectionFlags(flag.CommandLine)
+	tables := flag.String("tables", "", "comma separated tables to generate types for, all if empty")
+	pkg := flag.String("package", surrealgen.DefaultPackage, "package of the generated code")
+	output := flag.String("o", "", "file to write the generated code to, standard output if empty")
+	flag.Parse()
+
+	db, err := connection.Connect()
+	if err != nil {
+		cli.Fail("surreal-gen", err)
+	}
+	defer db.Close()
+
+	var names []string
+	if *tables != "" {
+		names = strings.Split(*tables, ",")
+	}
+	schema, err := surrealgen.Inspect(db, names...)
+	if err != nil {
+		cli.Fail("surreal-gen", err)
+	}
+	source, err := surrealgen.Generate(schema, &surrealgen.Options{Package: *pkg})
+	if err != nil {
+		cli.Fail("surreal-gen", err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(source)
+	} else {
+		err = os.WriteFile(*output, source, 0o644)
+	}
+	if err != nil {
+		cli.Fail("surreal-gen", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Command surreal-migrate applies and reverts the migrations in a directory, see package migrate.
+//
+//	surreal-migrate -ns app -db app -user root -pass root -dir migrations up
+//	surreal-migrate -ns app -db app -user root -pass root -dir migrations down 2
+//	surreal-migrate -ns app -db app -user root -pass root -dir migrations status
+//	surreal-migrate -ns app -db app -user root -pass root unlock
+//
+// With -dry-run, up and down print the statements they would run instead of running them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/terawatthour/surreal-go/internal/cli"
+	"github.com/terawatthour/surreal-go/migrate"
+	"os"
+	"strconv"
+)
+
+func main() {
+	connection := cli.RegisterConnectionFlags(flag.CommandLine)
+	dir := flag.String("dir", "migrations", "directory the migrations are read from")
+	table := flag.String("table", migrate.DefaultTable, "table applied migrations are recorded in")
+	dryRun := flag.Bool("dry-run", false, "print the statements instead of running them")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: surreal-migrate [flags] up | down [steps] | status | unlock\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(connection, *dir, *table, *dryRun, flag.Args()); err != nil {
+		cli.Fail("surreal-migrate", err)
+	}
+}
+
+func run(connection *cli.ConnectionFlags, dir, table string, dryRun bool, args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	options := &migrate.Options{Table: table}
+	if dryRun {
+		options.DryRun = os.Stdout
+	}
+
+	db, err := connection.Connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, os.DirFS(dir), options)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up()
+		report("applied", done, dryRun)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %s", args[1])
+			}
+		}
+		done, err := migrator.Down(steps)
+		report("reverted", done, dryRun)
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%s_%s\t%s\n", status.Version, status.Name, state)
+		}
+		return nil
+	case "unlock":
+		return migrator.Unlock()
+	default:
+		return fmt.Errorf("unknown command %s, expected up, down, status or unlock", args[0])
+	}
+}
+
+func report(verb string, migrations []migrate.Migration, dryRun bool) {
+	if dryRun {
+		return
+	}
+	for _, migration := range migrations {
+		fmt.Printf("%s %s_%s\n", verb, migration.Version, migration.Name)
+	}
+	if len(migrations) == 0 {
+		fmt.Printf("nothing %s\n", verb)
+	}
+}
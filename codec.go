@@ -0,0 +1,190 @@
+package surreal
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/terawatthour/surreal-go/rpc"
+)
+
+// Encoding selects the format RPC messages are exchanged in.
+type Encoding int
+
+const (
+	// EncodingJSON exchanges messages as JSON. Record ids, datetimes, durations, decimals and UUIDs arrive as plain
+	// strings or numbers, NONE and NULL are indistinguishable.
+	EncodingJSON Encoding = iota
+
+	// EncodingCBOR exchanges messages as CBOR, using SurrealDB's custom tags to preserve the types of record ids,
+	// datetimes, durations, decimals, UUIDs, geometries, ranges and NONE. Such values decode into the corresponding
+	// types of this package (RecordID, Datetime, Duration, ...), either directly or when the destination is `any`.
+	EncodingCBOR
+)
+
+// codec encodes and decodes RPC messages, along with the values they carry, in a single Encoding.
+type codec interface {
+	marshal(v any) ([]byte, error)
+	unmarshal(data []byte, v any) error
+
+	// shape reports what kind of value raw holds without decoding it.
+	shape(raw []byte) shape
+
+	// subprotocol is the websocket subprotocol negotiated for the encoding.
+	subprotocol() string
+
+	// contentType is the media type HTTP requests and responses are exchanged in.
+	contentType() string
+}
+
+type shape int
+
+const (
+	shapeOther shape = iota
+	shapeNull
+	shapeArray
+	shapeObject
+)
+
+func (e Encoding) codec() codec {
+	switch e {
+	case EncodingCBOR:
+		return cborCodec{}
+	default:
+		return jsonCodec{}
+	}
+}
+
+// Marshal encodes v in the encoding, the way call params are sent. Meant for interceptors answering calls themselves.
+func (e Encoding) Marshal(v any) ([]byte, error) {
+	return e.codec().marshal(v)
+}
+
+// Unmarshal decodes data, a result in the encoding, into v. Meant for interceptors inspecting results.
+func (e Encoding) Unmarshal(data []byte, v any) error {
+	return e.codec().unmarshal(data, v)
+}
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingJSON:
+		return "json"
+	case EncodingCBOR:
+		return "cbor"
+	default:
+		return fmt.Sprintf("Encoding(%d)", int(e))
+	}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) shape(raw []byte) shape {
+	for _, b := range raw {
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '[':
+			return shapeArray
+		case '{':
+			return shapeObject
+		case 'n':
+			return shapeNull
+		default:
+			return shapeOther
+		}
+	}
+	return shapeNull
+}
+
+func (jsonCodec) subprotocol() string {
+	return "json"
+}
+
+func (jsonCodec) contentType() string {
+	return "application/json"
+}
+
+type cborCodec struct{}
+
+func (cborCodec) marshal(v any) ([]byte, error) {
+	return cborEncMode.Marshal(v)
+}
+
+func (cborCodec) unmarshal(data []byte, v any) error {
+	return cborDecMode.Unmarshal(data, v)
+}
+
+func (cborCodec) shape(raw []byte) shape {
+	if len(raw) == 0 {
+		return shapeNull
+	}
+
+	switch {
+	case raw[0] == 0xf6, raw[0] == 0xf7, raw[0] == 0xc0|tagNone:
+		// null, undefined and NONE
+		return shapeNull
+	case raw[0]>>5 == 4:
+		return shapeArray
+	case raw[0]>>5 == 5:
+		return shapeObject
+	default:
+		return shapeOther
+	}
+}
+
+func (cborCodec) subprotocol() string {
+	return "cbor"
+}
+
+func (cborCodec) contentType() string {
+	return "application/cbor"
+}
+
+// decodeString decodes a value that is expected to be a string, or anything with a sensible string representation,
+// like the UUIDs live queries are identified by.
+func decodeString(c codec, raw []byte) (string, error) {
+	var value any
+	if err := c.unmarshal(raw, &value); err != nil {
+		return "", err
+	}
+
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case fmt.Stringer:
+		return value.String(), nil
+	default:
+		return "", fmt.Errorf("expected a string, got %T", value)
+	}
+}
+
+// decodeNotification decodes the live notification carried in the result of an incoming message.
+func decodeNotification(c codec, raw []byte) (rpc.LiveNotification, error) {
+	var notification struct {
+		ID     rpc.RawMessage `json:"id"`
+		Action rpc.Action     `json:"action"`
+		Record rpc.RawMessage `json:"record"`
+		Result rpc.RawMessage `json:"result"`
+	}
+	if err := c.unmarshal(raw, &notification); err != nil {
+		return rpc.LiveNotification{}, err
+	}
+
+	id, err := decodeString(c, notification.ID)
+	if err != nil {
+		return rpc.LiveNotification{}, fmt.Errorf("invalid live query id: %s", err)
+	}
+
+	return rpc.LiveNotification{
+		ID:     id,
+		Action: notification.Action,
+		Record: notification.Record,
+		Result: notification.Result,
+	}, nil
+}
@@ -1,10 +1,17 @@
 package surreal
 
-import "github.com/terawatthour/surreal-go/rpc"
+import (
+	"context"
+	"github.com/terawatthour/surreal-go/rpc"
+)
 
 type Connection interface {
 	Run()
-	Send(method string, params []any) ([]byte, error)
+	// Send issues an RPC call and waits for its result. The call is abandoned, and ctx.Err() returned, once ctx is done.
+	Send(ctx context.Context, method string, params []any) ([]byte, error)
+	// RegisterLiveCallback routes the notifications of a live query to callback. Callbacks are invoked on a single
+	// goroutine, in the order notifications arrive, so a blocking callback holds back the notifications after it, and
+	// those that do not fit into the queue meanwhile are dropped.
 	RegisterLiveCallback(id string, callback func(notification rpc.LiveNotification))
 	Close() error
 }
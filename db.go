@@ -1,6 +1,7 @@
 package surreal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/terawatthour/surreal-go/rpc"
@@ -9,44 +10,80 @@ import (
 type DB struct {
 	conn    Connection
 	options *Options
+	codec   codec
 }
 
 // Use sets the namespace and database name for the current connection. Should be called after the connection is
 // established, but before any queries are sent.
 func (db *DB) Use(namespace, databaseName string) error {
-	_, err := db.conn.Send("use", []any{namespace, databaseName})
+	return db.UseContext(context.Background(), namespace, databaseName)
+}
+
+// UseContext is like Use, but the request is abandoned once ctx is done.
+func (db *DB) UseContext(ctx context.Context, namespace, databaseName string) error {
+	_, err := db.conn.Send(ctx, "use", []any{namespace, databaseName})
 	return err
 }
 
 // Let binds an identifier to a value. The value may be used in subsequent queries.
 func (db *DB) Let(identifier string, value any) error {
-	_, err := db.conn.Send("let", []any{identifier, value})
+	return db.LetContext(context.Background(), identifier, value)
+}
+
+// LetContext is like Let, but the request is abandoned once ctx is done.
+func (db *DB) LetContext(ctx context.Context, identifier string, value any) error {
+	_, err := db.conn.Send(ctx, "let", []any{identifier, value})
 	return err
 }
 
 // Unset removes an identifier from the current session.
 func (db *DB) Unset(identifier string) error {
-	_, err := db.conn.Send("unset", []any{identifier})
+	return db.UnsetContext(context.Background(), identifier)
+}
+
+// UnsetContext is like Unset, but the request is abandoned once ctx is done.
+func (db *DB) UnsetContext(ctx context.Context, identifier string) error {
+	_, err := db.conn.Send(ctx, "unset", []any{identifier})
 	return err
 }
 
 func (db *DB) SignIn(args AuthArgs) error {
-	_, err := db.conn.Send("signin", []any{args})
+	return db.SignInContext(context.Background(), args)
+}
+
+// SignInContext is like SignIn, but the request is abandoned once ctx is done.
+func (db *DB) SignInContext(ctx context.Context, args AuthArgs) error {
+	_, err := db.conn.Send(ctx, "signin", []any{args})
 	return err
 }
 
 func (db *DB) SignUp(args AuthArgs) error {
-	_, err := db.conn.Send("signup", []any{args})
+	return db.SignUpContext(context.Background(), args)
+}
+
+// SignUpContext is like SignUp, but the request is abandoned once ctx is done.
+func (db *DB) SignUpContext(ctx context.Context, args AuthArgs) error {
+	_, err := db.conn.Send(ctx, "signup", []any{args})
 	return err
 }
 
 func (db *DB) Authenticate(token string) error {
-	_, err := db.conn.Send("authenticate", []any{token})
+	return db.AuthenticateContext(context.Background(), token)
+}
+
+// AuthenticateContext is like Authenticate, but the request is abandoned once ctx is done.
+func (db *DB) AuthenticateContext(ctx context.Context, token string) error {
+	_, err := db.conn.Send(ctx, "authenticate", []any{token})
 	return err
 }
 
 func (db *DB) Invalidate() error {
-	_, err := db.conn.Send("invalidate", nil)
+	return db.InvalidateContext(context.Background())
+}
+
+// InvalidateContext is like Invalidate, but the request is abandoned once ctx is done.
+func (db *DB) InvalidateContext(ctx context.Context) error {
+	_, err := db.conn.Send(ctx, "invalidate", nil)
 	return err
 }
 
@@ -54,65 +91,124 @@ func (db *DB) Invalidate() error {
 // The result is decoded into the scanDestinations. If there are multiple queries, the results are decoded into the
 // corresponding scanDestinations. `vars` is a map of variables that are used to bind the query (or queries).
 func (db *DB) Query(query string, vars Map, scanDestinations ...any) error {
-	raw, err := db.conn.Send("query", []any{query, vars})
+	return db.QueryContext(context.Background(), query, vars, scanDestinations...)
+}
+
+// QueryContext is like Query, but the request is abandoned once ctx is done.
+func (db *DB) QueryContext(ctx context.Context, query string, vars Map, scanDestinations ...any) error {
+	rawQueryResult, err := db.query(ctx, query, vars)
 	if err != nil {
 		return err
 	}
 
-	var rawQueryResult rpc.RawResult
-	if err := json.Unmarshal(raw, &rawQueryResult); err != nil {
-		return fmt.Errorf("failed to decode result: %s", err)
-	}
+	return db.scanQueryResult(rawQueryResult, scanDestinations)
+}
 
-	var errors QueryErrors
-	for i, row := range rawQueryResult {
-		if !row.OK {
-			errors = append(errors, QueryError{i, string(row.Result)})
+// scanQueryResult decodes the results of a query's statements into the corresponding scanDestinations.
+func (db *DB) scanQueryResult(rawQueryResult rpc.RawResult, scanDestinations []any) error {
+	for i := 0; i < len(scanDestinations) && i < len(rawQueryResult); i++ {
+		if err := decode(db.codec, rawQueryResult[i].Result, scanDestinations[i]); err != nil {
+			return fmt.Errorf("failed to decode result of %d query: %s", i, err)
 		}
 	}
 
-	if len(errors) > 0 {
-		return errors
+	return nil
+}
+
+// Statement is a query built programmatically, e.g. with the builder package, along with the variables it binds.
+type Statement interface {
+	Build() (query string, vars Map, err error)
+}
+
+// Execute builds a statement and sends it like Query, decoding the results into the scanDestinations. Nothing is sent
+// if the statement fails to build.
+func (db *DB) Execute(statement Statement, scanDestinations ...any) error {
+	return db.ExecuteContext(context.Background(), statement, scanDestinations...)
+}
+
+// ExecuteContext is like Execute, but the request is abandoned once ctx is done.
+func (db *DB) ExecuteContext(ctx context.Context, statement Statement, scanDestinations ...any) error {
+	query, vars, err := statement.Build()
+	if err != nil {
+		return err
+	}
+	return db.QueryContext(ctx, query, vars, scanDestinations...)
+}
+
+// query sends a query and returns the results of its statements, or QueryErrors if any of them failed.
+func (db *DB) query(ctx context.Context, query string, vars Map) (rpc.RawResult, error) {
+	rawQueryResult, err := db.rawQuery(ctx, query, vars)
+	if err != nil {
+		return nil, err
 	}
 
-	for i := 0; i < len(scanDestinations) && i < len(rawQueryResult); i++ {
-		if err := json.Unmarshal(rawQueryResult[i].Result, scanDestinations[i]); err != nil {
-			return fmt.Errorf("failed to decode result of %d query: %s", i, err)
-		}
+	if err := db.newQueryResults(rawQueryResult).Err(); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return rawQueryResult, nil
+}
+
+// rawQuery sends a query and returns the results of its statements, whether they succeeded or not.
+func (db *DB) rawQuery(ctx context.Context, query string, vars Map) (rpc.RawResult, error) {
+	raw, err := db.conn.Send(ctx, "query", []any{query, vars})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.decodeQueryResult(raw)
+}
+
+// decodeQueryResult decodes the response to a query call into the results of its statements.
+func (db *DB) decodeQueryResult(raw []byte) (rpc.RawResult, error) {
+	var rawQueryResult rpc.RawResult
+	if err := db.codec.unmarshal(raw, &rawQueryResult); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %s", err)
+	}
+
+	return rawQueryResult, nil
 }
 
 // Select performs a select query and decodes the results into the destination. May target a single record or all
 // records in a table. Returns error if id is not a table name and there is no row found.
-func (db *DB) Select(id string, destination any) error {
-	raw, err := db.conn.Send("select", []any{id})
+// Destination may be either a pointer to a slice or a pointer to a single record (struct, map).
+//
+// Wherever DB methods target a record or a table, they accept a table name, a record id string such as
+// `article:1` or a RecordID.
+func (db *DB) Select(id any, destination any) error {
+	return db.SelectContext(context.Background(), id, destination)
+}
+
+// SelectContext is like Select, but the request is abandoned once ctx is done.
+func (db *DB) SelectContext(ctx context.Context, id any, destination any) error {
+	raw, err := db.conn.Send(ctx, "select", []any{db.thing(id)})
 	if err != nil {
 		return err
 	}
 
-	if string(raw) == "null" {
-		return fmt.Errorf("record not found")
-	}
-
-	if err := json.Unmarshal(raw, destination); err != nil {
-		return fmt.Errorf("failed to decode result: %s", err)
+	if db.codec.shape(raw) == shapeNull {
+		return ErrNotFound
 	}
 
-	return nil
+	return autoScan(db.codec, raw, destination)
 }
 
-// Create creates a record in a table, then decodes the row into the destination, if provided.
+// Create creates a record in a table, or with the given record id, then decodes the row into the destination, if
+// provided.
 // Destination may be either a pointer to a slice or a pointer to a single record (struct, map).
-func (db *DB) Create(table string, data any, destination ...any) error {
-	raw, err := db.conn.Send("create", []any{table, data})
+func (db *DB) Create(what any, data any, destination ...any) error {
+	return db.CreateContext(context.Background(), what, data, destination...)
+}
+
+// CreateContext is like Create, but the request is abandoned once ctx is done.
+func (db *DB) CreateContext(ctx context.Context, what any, data any, destination ...any) error {
+	raw, err := db.conn.Send(ctx, "create", []any{db.thing(what), data})
 	if err != nil {
 		return err
 	}
 
 	if len(destination) != 0 {
-		return autoScan(raw, destination[0])
+		return autoScan(db.codec, raw, destination[0])
 	}
 
 	return nil
@@ -121,114 +217,163 @@ func (db *DB) Create(table string, data any, destination ...any) error {
 // Insert inserts a record, or multiple records, into a table, then decodes the rows into the destination, if provided.
 // Destination may be either a pointer to a slice or a pointer to a single record (struct, map).
 func (db *DB) Insert(table string, data any, destination ...any) error {
-	raw, err := db.conn.Send("insert", []any{table, data})
+	return db.InsertContext(context.Background(), table, data, destination...)
+}
+
+// InsertContext is like Insert, but the request is abandoned once ctx is done.
+func (db *DB) InsertContext(ctx context.Context, table string, data any, destination ...any) error {
+	raw, err := db.conn.Send(ctx, "insert", []any{table, data})
 	if err != nil {
 		return err
 	}
 
 	if len(destination) != 0 {
-		return autoScan(raw, destination[0])
+		return autoScan(db.codec, raw, destination[0])
 	}
 
 	return nil
 }
 
 func (db *DB) Relate(from any, thing string, to any, data any, destination ...any) error {
-	raw, err := db.conn.Send("relate", []any{from, thing, to, data})
+	return db.RelateContext(context.Background(), from, thing, to, data, destination...)
+}
+
+// RelateContext is like Relate, but the request is abandoned once ctx is done.
+func (db *DB) RelateContext(ctx context.Context, from any, thing string, to any, data any, destination ...any) error {
+	raw, err := db.conn.Send(ctx, "relate", []any{db.thing(from), thing, db.thing(to), data})
 	if err != nil {
 		return err
 	}
 
 	if len(destination) != 0 {
-		return autoScan(raw, destination[0])
+		return autoScan(db.codec, raw, destination[0])
 	}
 
 	return nil
 }
 
-func (db *DB) Update(id string, data any, destination ...any) error {
-	raw, err := db.conn.Send("update", []any{id, data})
+func (db *DB) Update(id any, data any, destination ...any) error {
+	return db.UpdateContext(context.Background(), id, data, destination...)
+}
+
+// UpdateContext is like Update, but the request is abandoned once ctx is done.
+func (db *DB) UpdateContext(ctx context.Context, id any, data any, destination ...any) error {
+	raw, err := db.conn.Send(ctx, "update", []any{db.thing(id), data})
 	if err != nil {
 		return err
 	}
 
 	if len(destination) != 0 {
-		return autoScan(raw, destination[0])
+		return autoScan(db.codec, raw, destination[0])
 	}
 
 	return nil
 }
 
-func (db *DB) Upsert(id string, data any, destination ...any) error {
-	raw, err := db.conn.Send("upsert", []any{id, data})
+func (db *DB) Upsert(id any, data any, destination ...any) error {
+	return db.UpsertContext(context.Background(), id, data, destination...)
+}
+
+// UpsertContext is like Upsert, but the request is abandoned once ctx is done.
+func (db *DB) UpsertContext(ctx context.Context, id any, data any, destination ...any) error {
+	raw, err := db.conn.Send(ctx, "upsert", []any{db.thing(id), data})
 	if err != nil {
 		return err
 	}
 
 	if len(destination) != 0 {
-		return autoScan(raw, destination[0])
+		return autoScan(db.codec, raw, destination[0])
 	}
 
 	return nil
 }
 
+// Live starts a live query on a table and invokes callback, each time on a fresh goroutine, with its notifications.
+// Use Subscribe to receive them in order instead.
 func (db *DB) Live(id string, callback func(notification rpc.LiveNotification), diff bool) (string, error) {
-	raw, err := db.conn.Send("live", []any{id, diff})
+	return db.LiveContext(context.Background(), id, callback, diff)
+}
+
+// LiveContext is like Live, but the request is abandoned once ctx is done. Cancelling ctx after the live query has
+// been started does not kill it, use Kill for that.
+func (db *DB) LiveContext(ctx context.Context, id string, callback func(notification rpc.LiveNotification), diff bool) (string, error) {
+	raw, err := db.conn.Send(ctx, "live", []any{id, diff})
 	if err != nil {
 		return "", err
 	}
 
-	if raw[0] == '"' {
-		id := string(raw[1 : len(raw)-1])
-
-		db.conn.RegisterLiveCallback(id, callback)
-
-		return id, nil
+	id, err = decodeString(db.codec, raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to start live query: %s", err)
 	}
 
-	return "", fmt.Errorf("failed to start live query")
+	db.conn.RegisterLiveCallback(id, func(notification rpc.LiveNotification) {
+		go callback(notification)
+	})
+
+	return id, nil
 }
 
 func (db *DB) Kill(id string) error {
-	_, err := db.conn.Send("kill", []any{id})
+	return db.KillContext(context.Background(), id)
+}
+
+// KillContext is like Kill, but the request is abandoned once ctx is done.
+func (db *DB) KillContext(ctx context.Context, id string) error {
+	_, err := db.conn.Send(ctx, "kill", []any{id})
 	return err
 }
 
-func (db *DB) Patch(id string, diff []Diff, destination ...any) error {
-	raw, err := db.conn.Send("patch", []any{id, diff})
+func (db *DB) Patch(id any, diff []Diff, destination ...any) error {
+	return db.PatchContext(context.Background(), id, diff, destination...)
+}
+
+// PatchContext is like Patch, but the request is abandoned once ctx is done.
+func (db *DB) PatchContext(ctx context.Context, id any, diff []Diff, destination ...any) error {
+	raw, err := db.conn.Send(ctx, "patch", []any{db.thing(id), diff})
 	if err != nil {
 		return err
 	}
 
 	if len(destination) != 0 {
-		return autoScan(raw, destination[0])
+		return autoScan(db.codec, raw, destination[0])
 	}
 
 	return nil
 }
 
-func (db *DB) Merge(id string, data any, destination ...any) error {
-	raw, err := db.conn.Send("merge", []any{id, data})
+func (db *DB) Merge(id any, data any, destination ...any) error {
+	return db.MergeContext(context.Background(), id, data, destination...)
+}
+
+// MergeContext is like Merge, but the request is abandoned once ctx is done.
+func (db *DB) MergeContext(ctx context.Context, id any, data any, destination ...any) error {
+	raw, err := db.conn.Send(ctx, "merge", []any{db.thing(id), data})
 	if err != nil {
 		return err
 	}
 
 	if len(destination) != 0 {
-		return autoScan(raw, destination[0])
+		return autoScan(db.codec, raw, destination[0])
 	}
 
 	return nil
 }
 
 // Delete deletes a record, or all records, from a table, then decodes the rows into the destination, if provided.
-func (db *DB) Delete(id string, destination ...any) error {
-	raw, err := db.conn.Send("delete", []any{id})
+func (db *DB) Delete(id any, destination ...any) error {
+	return db.DeleteContext(context.Background(), id, destination...)
+}
+
+// DeleteContext is like Delete, but the request is abandoned once ctx is done.
+func (db *DB) DeleteContext(ctx context.Context, id any, destination ...any) error {
+	raw, err := db.conn.Send(ctx, "delete", []any{db.thing(id)})
 	if err != nil {
 		return err
 	}
 
 	if len(destination) != 0 {
-		return autoScan(raw, destination[0])
+		return autoScan(db.codec, raw, destination[0])
 	}
 
 	return nil
@@ -236,23 +381,41 @@ func (db *DB) Delete(id string, destination ...any) error {
 
 // Info retrieves information about the current scope(!) user.
 func (db *DB) Info(destination any) error {
-	raw, err := db.conn.Send("info", []any{})
+	return db.InfoContext(context.Background(), destination)
+}
+
+// InfoContext is like Info, but the request is abandoned once ctx is done.
+func (db *DB) InfoContext(ctx context.Context, destination any) error {
+	raw, err := db.conn.Send(ctx, "info", []any{})
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(raw, destination)
+	return decode(db.codec, raw, destination)
 }
 
 func (db *DB) Ping() error {
-	_, err := db.conn.Send("ping", []any{})
+	return db.PingContext(context.Background())
+}
+
+// PingContext is like Ping, but the request is abandoned once ctx is done.
+func (db *DB) PingContext(ctx context.Context) error {
+	_, err := db.conn.Send(ctx, "ping", []any{})
 	return err
 }
 
 // Version retrieves the version of the database.
 func (db *DB) Version() (string, error) {
-	raw, err := db.conn.Send("version", []any{})
-	return string(raw[1 : len(raw)-1]), err
+	return db.VersionContext(context.Background())
+}
+
+// VersionContext is like Version, but the request is abandoned once ctx is done.
+func (db *DB) VersionContext(ctx context.Context) (string, error) {
+	raw, err := db.conn.Send(ctx, "version", []any{})
+	if err != nil {
+		return "", err
+	}
+	return decodeString(db.codec, raw)
 }
 
 // Close closes the connection to the database.
@@ -275,15 +438,28 @@ type AuthArgs struct {
 }
 
 func (s AuthArgs) MarshalJSON() ([]byte, error) {
-	s.Other["NS"] = s.Namespace
-	s.Other["DB"] = s.Database
+	return json.Marshal(s.toMap())
+}
+
+func (s AuthArgs) MarshalCBOR() ([]byte, error) {
+	return cborEncMode.Marshal(s.toMap())
+}
+
+func (s AuthArgs) toMap() Map {
+	m := make(Map, len(s.Other)+3)
+	for k, v := range s.Other {
+		m[k] = v
+	}
+
+	m["NS"] = s.Namespace
+	m["DB"] = s.Database
 	if s.Scope != "" {
-		s.Other["SC"] = s.Scope
+		m["SC"] = s.Scope
 	} else if s.Access != "" {
-		s.Other["AC"] = s.Access
+		m["AC"] = s.Access
 	}
 
-	return json.Marshal(s.Other)
+	return m
 }
 
 type QueryError struct {
@@ -291,14 +467,32 @@ type QueryError struct {
 	Message string
 }
 
+func (e QueryError) Error() string {
+	return fmt.Sprintf("query %d failed with error: `%s`", e.QueryNo, e.Message)
+}
+
+// Unwrap returns the sentinel error, like ErrAlreadyExists, the message stands for, nil if there is none.
+func (e QueryError) Unwrap() error {
+	return classifyMessage(e.Message)
+}
+
 type QueryErrors []QueryError
 
 func (q QueryErrors) Error() string {
 	var s string
 	for _, e := range q {
-		s += fmt.Sprintf("query %d failed with error: `%s`; ", e.QueryNo, e.Message)
+		s += e.Error() + "; "
 	}
 	return s
 }
 
+// Unwrap returns the errors of the individual statements, so that errors.Is and errors.As look into each of them.
+func (q QueryErrors) Unwrap() []error {
+	errs := make([]error, len(q))
+	for i, e := range q {
+		errs[i] = e
+	}
+	return errs
+}
+
 type Map map[string]any
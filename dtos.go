@@ -2,6 +2,7 @@ package surreal
 
 import "encoding/json"
 
+// LiveDiff is a single JSON Patch operation, as carried by the notifications of live queries started with diff set.
 type LiveDiff struct {
 	Op    string          `json:"op"`
 	Path  string          `json:"path"`
@@ -0,0 +1,102 @@
+package surreal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/terawatthour/surreal-go/rpc"
+	"net"
+	"net/http"
+	"regexp"
+)
+
+// Errors returned by the driver wrap one of these where it applies, so that they can be told apart with errors.Is. The
+// original error, e.g. the *rpc.Error sent by the server, remains reachable with errors.As and its message is kept.
+var (
+	ErrNotFound      = errors.New("record not found")
+	ErrTimeout       = errors.New("request timed out")
+	ErrClosed        = errors.New("connection is closed")
+	ErrAuth          = errors.New("authentication failed")
+	ErrPermission    = errors.New("permission denied")
+	ErrTxConflict    = errors.New("transaction conflict")
+	ErrAlreadyExists = errors.New("record already exists")
+)
+
+// codeSentinels maps the codes of errors sent by the server onto the sentinel they stand for. Unlike plain JSON-RPC,
+// SurrealDB answers a method the session may not call with -32602, and invalid params with -32603. Failures of the
+// call itself share -32000 and are told apart by their message.
+var codeSentinels = map[int]error{
+	-32602: ErrPermission,
+}
+
+// errorPatterns maps the messages SurrealDB reports failures with onto the sentinel they stand for. Earlier entries
+// take precedence.
+var errorPatterns = []struct {
+	pattern  *regexp.Regexp
+	sentinel error
+}{
+	// Failed to commit transaction due to a read or write conflict. This transaction can be retried
+	{regexp.MustCompile(`(?i)due to a read or write conflict`), ErrTxConflict},
+	{regexp.MustCompile(`(?i)this transaction can be retried`), ErrTxConflict},
+	// The query was not executed because it exceeded the timeout
+	{regexp.MustCompile(`(?i)exceeded the timeout`), ErrTimeout},
+	// IAM error: Not enough permissions to perform this action
+	{regexp.MustCompile(`(?i)IAM error: not enough permissions`), ErrPermission},
+	// You don't have permission to run this query on the `user` table, or to change to the `test` namespace, ...
+	{regexp.MustCompile("(?i)you don't have permission to "), ErrPermission},
+	// There was a problem with authentication
+	{regexp.MustCompile(`(?i)there was a problem with authentication`), ErrAuth},
+	{regexp.MustCompile(`(?i)the (token|session) has expired`), ErrAuth},
+	// Database record `user:1` already exists
+	{regexp.MustCompile("(?i)database record `.*` already exists"), ErrAlreadyExists},
+	// Database index `email` already contains 'jane@example.com', with record `user:1`
+	{regexp.MustCompile("(?i)database index `.*` already contains .*, with record `"), ErrAlreadyExists},
+	// The record 'user:1' does not exist
+	{regexp.MustCompile(`(?i)the record '.*' does not exist`), ErrNotFound},
+}
+
+// classifyMessage returns the sentinel an error message reported by the server stands for, nil if there is none.
+func classifyMessage(message string) error {
+	for _, pattern := range errorPatterns {
+		if pattern.pattern.MatchString(message) {
+			return pattern.sentinel
+		}
+	}
+	return nil
+}
+
+// wrapRPCError wraps an error sent by the server with the sentinel its code, or failing that its message, stands for.
+func wrapRPCError(err *rpc.Error) error {
+	sentinel, ok := codeSentinels[err.Code]
+	if !ok {
+		sentinel = classifyMessage(err.Message)
+	}
+	if sentinel != nil {
+		return fmt.Errorf("%w: %w", sentinel, err)
+	}
+	return err
+}
+
+// wrapStatusError reports an HTTP response that carried no RPC result, wrapping the sentinel its status stands for.
+func wrapStatusError(status int, err error) error {
+	switch status {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: %w", ErrAuth, err)
+	case http.StatusForbidden:
+		return fmt.Errorf("%w: %w", ErrPermission, err)
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	default:
+		return err
+	}
+}
+
+// wrapNetError wraps network errors that are timeouts with ErrTimeout.
+func wrapNetError(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
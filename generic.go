@@ -0,0 +1,144 @@
+package surreal
+
+import (
+	"context"
+	"fmt"
+)
+
+// Select selects all records of a table, or a single record, and decodes them into a slice of T.
+func Select[T any](db *DB, what any) ([]T, error) {
+	return SelectContext[T](context.Background(), db, what)
+}
+
+// SelectContext is like Select, but the request is abandoned once ctx is done.
+func SelectContext[T any](ctx context.Context, db *DB, what any) ([]T, error) {
+	var rows []T
+	if err := db.SelectContext(ctx, what, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// SelectOne selects a single record and decodes it into T. Returns error if there is no such record.
+func SelectOne[T any](db *DB, id any) (T, error) {
+	return SelectOneContext[T](context.Background(), db, id)
+}
+
+// SelectOneContext is like SelectOne, but the request is abandoned once ctx is done.
+func SelectOneContext[T any](ctx context.Context, db *DB, id any) (T, error) {
+	var zero T
+
+	rows, err := SelectContext[T](ctx, db, id)
+	if err != nil {
+		return zero, err
+	}
+	if len(rows) == 0 {
+		return zero, ErrNotFound
+	}
+
+	return rows[0], nil
+}
+
+// Create creates a record in a table, or with the given record id, and returns it decoded into T.
+func Create[T any](db *DB, what any, data any) (T, error) {
+	return CreateContext[T](context.Background(), db, what, data)
+}
+
+// CreateContext is like Create, but the request is abandoned once ctx is done.
+func CreateContext[T any](ctx context.Context, db *DB, what any, data any) (T, error) {
+	var row T
+	err := db.CreateContext(ctx, what, data, &row)
+	return row, err
+}
+
+// Insert inserts a record, or multiple records, into a table and returns them decoded into a slice of T.
+func Insert[T any](db *DB, table string, data any) ([]T, error) {
+	return InsertContext[T](context.Background(), db, table, data)
+}
+
+// InsertContext is like Insert, but the request is abandoned once ctx is done.
+func InsertContext[T any](ctx context.Context, db *DB, table string, data any) ([]T, error) {
+	var rows []T
+	if err := db.InsertContext(ctx, table, data, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Update replaces the contents of a record, or of all records in a table, and returns them decoded into a slice of T.
+func Update[T any](db *DB, what any, data any) ([]T, error) {
+	return UpdateContext[T](context.Background(), db, what, data)
+}
+
+// UpdateContext is like Update, but the request is abandoned once ctx is done.
+func UpdateContext[T any](ctx context.Context, db *DB, what any, data any) ([]T, error) {
+	var rows []T
+	if err := db.UpdateContext(ctx, what, data, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Merge merges data into a record, or into all records in a table, and returns them decoded into a slice of T.
+func Merge[T any](db *DB, what any, data any) ([]T, error) {
+	return MergeContext[T](context.Background(), db, what, data)
+}
+
+// MergeContext is like Merge, but the request is abandoned once ctx is done.
+func MergeContext[T any](ctx context.Context, db *DB, what any, data any) ([]T, error) {
+	var rows []T
+	if err := db.MergeContext(ctx, what, data, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Delete deletes a record, or all records in a table, and returns them decoded into a slice of T.
+func Delete[T any](db *DB, what any) ([]T, error) {
+	return DeleteContext[T](context.Background(), db, what)
+}
+
+// DeleteContext is like Delete, but the request is abandoned once ctx is done.
+func DeleteContext[T any](ctx context.Context, db *DB, what any) ([]T, error) {
+	var rows []T
+	if err := db.DeleteContext(ctx, what, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// QueryAs sends a query and decodes the result of its last statement into a slice of T. Earlier statements, like
+// LET, are only checked for errors.
+func QueryAs[T any](db *DB, query string, vars Map) ([]T, error) {
+	return QueryAsContext[T](context.Background(), db, query, vars)
+}
+
+// QueryAsContext is like QueryAs, but the request is abandoned once ctx is done.
+func QueryAsContext[T any](ctx context.Context, db *DB, query string, vars Map) ([]T, error) {
+	results, err := db.query(ctx, query, vars)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	raw := results[len(results)-1].Result
+	switch db.codec.shape(raw) {
+	case shapeNull:
+		return nil, nil
+	case shapeOther:
+		// RETURN statements may yield a single scalar
+		var row T
+		if err := decode(db.codec, raw, &row); err != nil {
+			return nil, fmt.Errorf("failed to decode result: %s", err)
+		}
+		return []T{row}, nil
+	}
+
+	var rows []T
+	if err := autoScan(db.codec, raw, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,319 @@
+package surreal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Geometry is implemented by all geometry types: Point, LineString, Polygon, MultiPoint, MultiLineString,
+// MultiPolygon and GeometryCollection. With EncodingJSON they are exchanged as GeoJSON objects, the shape SurrealDB
+// reads and writes geometries in, and with EncodingCBOR under SurrealDB's geometry tags.
+type Geometry interface {
+	// geoJSON returns the geometry as a GeoJSON object.
+	geoJSON() map[string]any
+}
+
+// Point is a geographic point, given as longitude and latitude, in that order.
+type Point [2]float64
+
+// LineString is a line made of two or more points.
+type LineString []Point
+
+// Polygon is a shape made of closed lines, the first one being the outer boundary, the rest being holes cut out of it.
+type Polygon []LineString
+
+// MultiPoint is a set of points.
+type MultiPoint []Point
+
+// MultiLineString is a set of lines.
+type MultiLineString []LineString
+
+// MultiPolygon is a set of polygons.
+type MultiPolygon []Polygon
+
+// GeometryCollection is a set of geometries of any type.
+type GeometryCollection []Geometry
+
+// String formats the point as a SurrealQL literal, e.g. (-0.118092, 51.509865).
+func (p Point) String() string {
+	return "(" + strconv.FormatFloat(p[0], 'g', -1, 64) + ", " + strconv.FormatFloat(p[1], 'g', -1, 64) + ")"
+}
+
+func (p Point) geoJSON() map[string]any {
+	return map[string]any{"type": "Point", "coordinates": [2]float64(p)}
+}
+
+func (l LineString) geoJSON() map[string]any {
+	return map[string]any{"type": "LineString", "coordinates": pointCoordinates(l)}
+}
+
+func (p Polygon) geoJSON() map[string]any {
+	return map[string]any{"type": "Polygon", "coordinates": polygonCoordinates(p)}
+}
+
+func (m MultiPoint) geoJSON() map[string]any {
+	return map[string]any{"type": "MultiPoint", "coordinates": pointCoordinates(m)}
+}
+
+func (m MultiLineString) geoJSON() map[string]any {
+	return map[string]any{"type": "MultiLineString", "coordinates": polygonCoordinates(m)}
+}
+
+func (m MultiPolygon) geoJSON() map[string]any {
+	coordinates := make([][][][2]float64, len(m))
+	for i, polygon := range m {
+		coordinates[i] = polygonCoordinates(polygon)
+	}
+	return map[string]any{"type": "MultiPolygon", "coordinates": coordinates}
+}
+
+func (c GeometryCollection) geoJSON() map[string]any {
+	geometries := make([]map[string]any, len(c))
+	for i, geometry := range c {
+		geometries[i] = geometry.geoJSON()
+	}
+	return map[string]any{"type": "GeometryCollection", "geometries": geometries}
+}
+
+// pointCoordinates returns the coordinates of points as plain arrays, which marshal as such rather than as GeoJSON
+// points.
+func pointCoordinates(points []Point) [][2]float64 {
+	coordinates := make([][2]float64, len(points))
+	for i, point := range points {
+		coordinates[i] = point
+	}
+	return coordinates
+}
+
+func polygonCoordinates(lines []LineString) [][][2]float64 {
+	coordinates := make([][][2]float64, len(lines))
+	for i, line := range lines {
+		coordinates[i] = pointCoordinates(line)
+	}
+	return coordinates
+}
+
+// unmarshalGeoJSON decodes a GeoJSON object of the given type into coordinates. Bare coordinates are accepted too, which
+// is how the points of a line, the lines of a polygon and so on are decoded.
+func unmarshalGeoJSON(data []byte, kind string, coordinates any) error {
+	switch (jsonCodec{}).shape(data) {
+	case shapeNull:
+		return nil
+	case shapeArray:
+		return json.Unmarshal(data, coordinates)
+	}
+
+	var object struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("invalid %s: %s", kind, err)
+	}
+	if object.Type != kind {
+		return fmt.Errorf("invalid %s: got GeoJSON type %q", kind, object.Type)
+	}
+	if err := json.Unmarshal(object.Coordinates, coordinates); err != nil {
+		return fmt.Errorf("invalid %s: %s", kind, err)
+	}
+
+	return nil
+}
+
+// ParseGeoJSON decodes a GeoJSON geometry of any type, e.g. one found in a result decoded into a map.
+func ParseGeoJSON(data []byte) (Geometry, error) {
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("invalid geometry: %s", err)
+	}
+
+	switch object.Type {
+	case "Point":
+		return decodeGeoJSON[Point](data)
+	case "LineString":
+		return decodeGeoJSON[LineString](data)
+	case "Polygon":
+		return decodeGeoJSON[Polygon](data)
+	case "MultiPoint":
+		return decodeGeoJSON[MultiPoint](data)
+	case "MultiLineString":
+		return decodeGeoJSON[MultiLineString](data)
+	case "MultiPolygon":
+		return decodeGeoJSON[MultiPolygon](data)
+	case "GeometryCollection":
+		return decodeGeoJSON[GeometryCollection](data)
+	default:
+		return nil, fmt.Errorf("invalid geometry: unknown GeoJSON type %q", object.Type)
+	}
+}
+
+func decodeGeoJSON[T Geometry, P interface {
+	*T
+	json.Unmarshaler
+}](data []byte) (Geometry, error) {
+	var geometry T
+	if err := P(&geometry).UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return geometry, nil
+}
+
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.geoJSON())
+}
+
+// UnmarshalJSON decodes a point from a GeoJSON object or from a [longitude, latitude] array.
+func (p *Point) UnmarshalJSON(data []byte) error {
+	return unmarshalGeoJSON(data, "Point", (*[2]float64)(p))
+}
+
+func (l LineString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.geoJSON())
+}
+
+func (l *LineString) UnmarshalJSON(data []byte) error {
+	return unmarshalGeoJSON(data, "LineString", (*[]Point)(l))
+}
+
+func (p Polygon) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.geoJSON())
+}
+
+func (p *Polygon) UnmarshalJSON(data []byte) error {
+	return unmarshalGeoJSON(data, "Polygon", (*[]LineString)(p))
+}
+
+func (m MultiPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.geoJSON())
+}
+
+func (m *MultiPoint) UnmarshalJSON(data []byte) error {
+	return unmarshalGeoJSON(data, "MultiPoint", (*[]Point)(m))
+}
+
+func (m MultiLineString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.geoJSON())
+}
+
+func (m *MultiLineString) UnmarshalJSON(data []byte) error {
+	return unmarshalGeoJSON(data, "MultiLineString", (*[]LineString)(m))
+}
+
+func (m MultiPolygon) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.geoJSON())
+}
+
+func (m *MultiPolygon) UnmarshalJSON(data []byte) error {
+	return unmarshalGeoJSON(data, "MultiPolygon", (*[]Polygon)(m))
+}
+
+func (c GeometryCollection) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.geoJSON())
+}
+
+func (c *GeometryCollection) UnmarshalJSON(data []byte) error {
+	if (jsonCodec{}).shape(data) == shapeNull {
+		return nil
+	}
+
+	var object struct {
+		Type       string            `json:"type"`
+		Geometries []json.RawMessage `json:"geometries"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("invalid GeometryCollection: %s", err)
+	}
+	if object.Type != "GeometryCollection" {
+		return fmt.Errorf("invalid GeometryCollection: got GeoJSON type %q", object.Type)
+	}
+
+	*c = make(GeometryCollection, 0, len(object.Geometries))
+	for _, raw := range object.Geometries {
+		geometry, err := ParseGeoJSON(raw)
+		if err != nil {
+			return err
+		}
+		*c = append(*c, geometry)
+	}
+
+	return nil
+}
+
+func (p Point) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagGeometryPoint, [2]float64(p))
+}
+
+func (p *Point) UnmarshalCBOR(data []byte) error {
+	_, err := unmarshalTagged(data, (*[2]float64)(p), tagGeometryPoint)
+	return err
+}
+
+func (l LineString) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagGeometryLine, []Point(l))
+}
+
+func (l *LineString) UnmarshalCBOR(data []byte) error {
+	_, err := unmarshalTagged(data, (*[]Point)(l), tagGeometryLine)
+	return err
+}
+
+func (p Polygon) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagGeometryPolygon, []LineString(p))
+}
+
+func (p *Polygon) UnmarshalCBOR(data []byte) error {
+	_, err := unmarshalTagged(data, (*[]LineString)(p), tagGeometryPolygon)
+	return err
+}
+
+func (m MultiPoint) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagGeometryMultiPoint, []Point(m))
+}
+
+func (m *MultiPoint) UnmarshalCBOR(data []byte) error {
+	_, err := unmarshalTagged(data, (*[]Point)(m), tagGeometryMultiPoint)
+	return err
+}
+
+func (m MultiLineString) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagGeometryMultiLine, []LineString(m))
+}
+
+func (m *MultiLineString) UnmarshalCBOR(data []byte) error {
+	_, err := unmarshalTagged(data, (*[]LineString)(m), tagGeometryMultiLine)
+	return err
+}
+
+func (m MultiPolygon) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagGeometryMultiPolygon, []Polygon(m))
+}
+
+func (m *MultiPolygon) UnmarshalCBOR(data []byte) error {
+	_, err := unmarshalTagged(data, (*[]Polygon)(m), tagGeometryMultiPolygon)
+	return err
+}
+
+func (c GeometryCollection) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagGeometryCollection, []Geometry(c))
+}
+
+func (c *GeometryCollection) UnmarshalCBOR(data []byte) error {
+	var geometries []any
+	if _, err := unmarshalTagged(data, &geometries, tagGeometryCollection); err != nil {
+		return err
+	}
+
+	*c = make(GeometryCollection, 0, len(geometries))
+	for _, g := range geometries {
+		geometry, ok := g.(Geometry)
+		if !ok {
+			return fmt.Errorf("invalid geometry in collection: %v", g)
+		}
+		*c = append(*c, geometry)
+	}
+
+	return nil
+}
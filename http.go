@@ -0,0 +1,244 @@
+package surreal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	gonanoid "github.com/matoous/go-nanoid"
+	"github.com/terawatthour/surreal-go/rpc"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type HTTPOptions struct {
+	// Client is the client requests are issued with. Defaults to a client with a timeout of ResponseTimeout.
+	Client *http.Client
+
+	// ResponseTimeout is the duration to wait for a response before timing out. Defaults to 10 seconds.
+	// Ignored if Client is set.
+	ResponseTimeout time.Duration
+}
+
+func (o *HTTPOptions) client() *http.Client {
+	if o.Client != nil {
+		return o.Client
+	}
+	if o.ResponseTimeout == 0 {
+		return &http.Client{Timeout: DefaultTimeout}
+	}
+	return &http.Client{Timeout: o.ResponseTimeout}
+}
+
+// UnsupportedMethodError is returned for RPC methods that cannot be served by a transport, e.g. live queries over
+// HTTP.
+type UnsupportedMethodError struct {
+	Method    string
+	Transport string
+}
+
+func (e *UnsupportedMethodError) Error() string {
+	return fmt.Sprintf("method %s is not supported over %s", e.Method, e.Transport)
+}
+
+// HTTPConnection talks to the stateless /rpc HTTP endpoint. The session (namespace, database, token and variables
+// bound with Let) is kept on the client and sent along with every request.
+type HTTPConnection struct {
+	url     string
+	options *Options
+	client  *http.Client
+	codec   codec
+
+	lock      sync.Mutex
+	namespace string
+	database  string
+	token     string
+	vars      Map
+	closed    bool
+}
+
+func establishHTTPConnection(connectionUrl *url.URL, options *Options) (Connection, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	endpoint := *connectionUrl
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = "/rpc"
+	}
+
+	return &HTTPConnection{
+		url:     endpoint.String(),
+		options: options,
+		client:  options.HTTPOptions.client(),
+		codec:   options.Encoding.codec(),
+		vars:    make(Map),
+	}, nil
+}
+
+// Run is a no-op, HTTP connections have nothing to read in the background.
+func (h *HTTPConnection) Run() {}
+
+// Send issues a single request to the /rpc endpoint. Calls altering the session are served locally, except for
+// signin and signup, whose resulting token is remembered for subsequent requests.
+func (h *HTTPConnection) Send(ctx context.Context, method string, params []any) ([]byte, error) {
+	h.lock.Lock()
+	if h.closed {
+		h.lock.Unlock()
+		return nil, ErrClosed
+	}
+
+	switch method {
+	case "use":
+		if len(params) == 2 {
+			h.namespace, _ = params[0].(string)
+			h.database, _ = params[1].(string)
+		}
+		h.lock.Unlock()
+		return h.null()
+	case "let":
+		if len(params) == 2 {
+			if identifier, ok := params[0].(string); ok {
+				h.vars[identifier] = params[1]
+			}
+		}
+		h.lock.Unlock()
+		return h.null()
+	case "unset":
+		if len(params) == 1 {
+			if identifier, ok := params[0].(string); ok {
+				delete(h.vars, identifier)
+			}
+		}
+		h.lock.Unlock()
+		return h.null()
+	case "authenticate":
+		if len(params) == 1 {
+			h.token, _ = params[0].(string)
+		}
+		h.lock.Unlock()
+		return h.null()
+	case "invalidate":
+		h.token = ""
+		h.lock.Unlock()
+		return h.null()
+	case "live", "kill":
+		h.lock.Unlock()
+		return nil, &UnsupportedMethodError{Method: method, Transport: "http"}
+	case "query":
+		// variables bound with Let are merged into the query's own, the latter taking precedence
+		if len(params) == 2 && len(h.vars) != 0 {
+			vars := make(Map, len(h.vars))
+			for identifier, value := range h.vars {
+				vars[identifier] = value
+			}
+			if own, ok := params[1].(Map); ok {
+				for identifier, value := range own {
+					vars[identifier] = value
+				}
+			}
+			params = []any{params[0], vars}
+		}
+	}
+	namespace, database, token := h.namespace, h.database, h.token
+	h.lock.Unlock()
+
+	result, err := h.post(ctx, namespace, database, token, method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == "signin" || method == "signup" {
+		h.lock.Lock()
+		h.token = decodeToken(h.codec, result)
+		h.lock.Unlock()
+	}
+
+	return result, nil
+}
+
+func (h *HTTPConnection) post(ctx context.Context, namespace, database, token, method string, params []any) ([]byte, error) {
+	eventId, _ := gonanoid.Generate(Alphanumeric, 16)
+	body, err := h.codec.marshal(&rpc.Outgoing{
+		ID:     eventId,
+		Method: method,
+		Params: encodeParams(params),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", h.codec.contentType())
+	req.Header.Set("Content-Type", h.codec.contentType())
+	if namespace != "" {
+		req.Header.Set("Surreal-NS", namespace)
+	}
+	if database != "" {
+		req.Header.Set("Surreal-DB", database)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	sent := time.Now()
+	res, err := h.client.Do(req)
+	if threshold := h.options.SlowCallThreshold; threshold > 0 {
+		if elapsed := time.Since(sent); elapsed >= threshold {
+			h.options.logger().Warn("slow call", "url", h.url, "id", eventId, "method", method, "duration", elapsed)
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to send request: %w", wrapNetError(err))
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var incoming rpc.Incoming
+	if err := h.codec.unmarshal(raw, &incoming); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return nil, wrapStatusError(res.StatusCode, fmt.Errorf("unexpected response status %s: %s", res.Status, raw))
+		}
+		h.options.logger().Warn("failed to decode response", "url", h.url, "id", eventId, "method", method, "error", err)
+		return nil, fmt.Errorf("failed to decode response: %s", err)
+	}
+
+	if incoming.Error != nil {
+		return nil, wrapRPCError(incoming.Error)
+	}
+
+	return incoming.Result, nil
+}
+
+// null is the encoded result of calls served locally.
+func (h *HTTPConnection) null() ([]byte, error) {
+	return h.codec.marshal(nil)
+}
+
+// RegisterLiveCallback is a no-op, live queries are not supported over HTTP.
+func (h *HTTPConnection) RegisterLiveCallback(string, func(notification rpc.LiveNotification)) {}
+
+func (h *HTTPConnection) Close() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.closed = true
+	if h.options.HTTPOptions.Client == nil {
+		h.client.CloseIdleConnections()
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package surreal
+
+import "context"
+
+// Invoker sends a call on, to the next interceptor or, after the last one, to the connection.
+type Invoker func(ctx context.Context, method string, params []any) ([]byte, error)
+
+// Interceptor is invoked around every call a DB makes. It may inspect or alter the method, params and result, retry
+// the call by invoking next more than once, or answer it without invoking next at all, e.g. from a cache.
+//
+// Calls made by the connection itself, such as those restoring the session after a reconnect, are not intercepted.
+type Interceptor func(ctx context.Context, method string, params []any, next Invoker) ([]byte, error)
+
+// interceptedConnection runs the calls of a connection through a chain of interceptors.
+type interceptedConnection struct {
+	Connection
+	send Invoker
+}
+
+// intercept wraps conn so that calls go through interceptors, the first of which is invoked first.
+func intercept(conn Connection, interceptors []Interceptor) Connection {
+	if len(interceptors) == 0 {
+		return conn
+	}
+
+	send := Invoker(conn.Send)
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], send
+		send = func(ctx context.Context, method string, params []any) ([]byte, error) {
+			return interceptor(ctx, method, params, next)
+		}
+	}
+
+	return &interceptedConnection{Connection: conn, send: send}
+}
+
+func (c *interceptedConnection) Send(ctx context.Context, method string, params []any) ([]byte, error) {
+	return c.send(ctx, method, params)
+}
+
+// closed forwards to the wrapped connection. The returned channel is nil, and never ready, if it cannot tell.
+func (c *interceptedConnection) closed() <-chan struct{} {
+	if conn, ok := c.Connection.(interface{ closed() <-chan struct{} }); ok {
+		return conn.closed()
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Package cli holds what the commands of this module share: the flags selecting the server, the namespace, the
+// database and the user to sign in as.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/terawatthour/surreal-go"
+	"os"
+)
+
+// ConnectionFlags are the flags a command connects to SurrealDB with.
+type ConnectionFlags struct {
+	URL       string
+	Namespace string
+	Database  string
+	User      string
+	Pass      string
+	Level     string
+}
+
+// RegisterConnectionFlags defines -url, -ns, -db, -user, -pass and -level on flags.
+func RegisterConnectionFlags(flags *flag.FlagSet) *ConnectionFlags {
+	f := &ConnectionFlags{}
+	flags.StringVar(&f.URL, "url", "ws://localhost:8000/rpc", "url of the SurrealDB server")
+	flags.StringVar(&f.Namespace, "ns", "", "namespace to use")
+	flags.StringVar(&f.Database, "db", "", "database to use")
+	flags.StringVar(&f.User, "user", "", "user to sign in as, none to stay anonymous")
+	flags.StringVar(&f.Pass, "pass", "", "password of the user")
+	flags.StringVar(&f.Level, "level", "root", "level the user is defined at: root, ns or db")
+	return f
+}
+
+// Connect connects to the server, signs in and selects the namespace and database.
+func (f *ConnectionFlags) Connect() (*surreal.DB, error) {
+	if f.Namespace == "" || f.Database == "" {
+		return nil, fmt.Errorf("-ns and -db are required")
+	}
+
+	db, err := surreal.Connect(f.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	if f.User != "" {
+		args := surreal.AuthArgs{Other: surreal.Map{"user": f.User, "pass": f.Pass}}
+		switch f.Level {
+		case "root":
+		case "ns":
+			args.Namespace = f.Namespace
+		case "db":
+			args.Namespace, args.Database = f.Namespace, f.Database
+		default:
+			db.Close()
+			return nil, fmt.Errorf("unknown level %s, expected root, ns or db", f.Level)
+		}
+		if err := db.SignIn(args); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to sign in: %w", err)
+		}
+	}
+	if err := db.Use(f.Namespace, f.Database); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to use %s/%s: %w", f.Namespace, f.Database, err)
+	}
+
+	return db, nil
+}
+
+// Fail reports err on the standard error and exits.
+func Fail(command string, err error) {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", command, err)
+	os.Exit(1)
+}
@@ -0,0 +1,120 @@
+package surreal
+
+import "strings"
+
+// rewriteQuery walks a SurrealQL query, skipping strings, escaped identifiers and comments, and replaces the name of
+// every $parameter by what rename returns for it. It also reports the number of statements in the query, counting only
+// semicolons outside of blocks. Whatever follows the last statement, semicolons, comments or whitespace, is dropped.
+func rewriteQuery(query string, rename func(name string) string) (string, int) {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	statements := 0
+	pending := false
+	depth := 0
+	// end is the length of the output up to the end of the last token of the last statement
+	end := 0
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			next := skipQuoted(query, i, c)
+			b.WriteString(query[i:next])
+			i = next
+			pending = true
+			end = b.Len()
+			continue
+		case strings.HasPrefix(query[i:], "⟨"):
+			next := skipEscaped(query, i)
+			b.WriteString(query[i:next])
+			i = next
+			pending = true
+			end = b.Len()
+			continue
+		case c == '#' || strings.HasPrefix(query[i:], "--") || strings.HasPrefix(query[i:], "//"):
+			next := strings.IndexByte(query[i:], '\n')
+			if next < 0 {
+				next = len(query)
+			} else {
+				next += i
+			}
+			b.WriteString(query[i:next])
+			i = next
+			continue
+		case strings.HasPrefix(query[i:], "/*"):
+			next := strings.Index(query[i+2:], "*/")
+			if next < 0 {
+				next = len(query)
+			} else {
+				next += i + 4
+			}
+			b.WriteString(query[i:next])
+			i = next
+			continue
+		case c == '$':
+			next := i + 1
+			for next < len(query) && isIdentByte(query[next]) {
+				next++
+			}
+			b.WriteByte('$')
+			if name := query[i+1 : next]; name != "" {
+				b.WriteString(rename(name))
+			}
+			i = next
+			pending = true
+			end = b.Len()
+			continue
+		case c == '{' || c == '(' || c == '[':
+			depth++
+		case c == '}' || c == ')' || c == ']':
+			depth--
+		case c == ';' && depth == 0:
+			if pending {
+				statements++
+			}
+			pending = false
+		case c != ' ' && c != '\t' && c != '\r' && c != '\n':
+			pending = true
+		}
+
+		b.WriteByte(c)
+		i++
+		if pending {
+			end = b.Len()
+		}
+	}
+
+	if pending {
+		statements++
+	}
+
+	return b.String()[:end], statements
+}
+
+// skipQuoted returns the offset just past the string or identifier opening with quote at start.
+func skipQuoted(query string, start int, quote byte) int {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// skipEscaped returns the offset just past the ⟨⟩ escaped identifier opening at start.
+func skipEscaped(query string, start int) int {
+	for i := start + len("⟨"); i < len(query); i++ {
+		switch {
+		case query[i] == '\\':
+			i++
+		case strings.HasPrefix(query[i:], "⟩"):
+			return i + len("⟩")
+		}
+	}
+	return len(query)
+}
@@ -0,0 +1,490 @@
+package surreal
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// formatLiteral renders a value as a SurrealQL literal.
+func formatLiteral(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case NoneType:
+		return "NONE"
+	case string:
+		return quoteString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case RecordID:
+		return v.String()
+	case Table:
+		return escapeIdent(string(v))
+	case UUID:
+		return "u" + quoteString(v.String())
+	case Datetime:
+		return v.String()
+	case Duration:
+		return v.String()
+	case Decimal:
+		return string(v) + "dec"
+	case Point:
+		return v.String()
+	case Geometry:
+		return formatLiteral(v.geoJSON())
+	case Range:
+		return formatRange(v)
+	}
+
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = formatLiteral(value.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			break
+		}
+
+		keys := make([]string, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = escapeIdent(key) + ": " + formatLiteral(value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key())).Interface())
+		}
+		if len(fields) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(fields, ", ") + " }"
+	case reflect.Pointer:
+		if value.IsNil() {
+			return "NULL"
+		}
+		return formatLiteral(value.Elem().Interface())
+	}
+
+	return quoteString(fmt.Sprint(v))
+}
+
+func formatRange(r Range) string {
+	var b strings.Builder
+	if r.Begin != nil {
+		b.WriteString(formatLiteral(r.Begin.Value))
+		if !r.Begin.Inclusive {
+			b.WriteString(">")
+		}
+	}
+	b.WriteString("..")
+	if r.End != nil {
+		if r.End.Inclusive {
+			b.WriteString("=")
+		}
+		b.WriteString(formatLiteral(r.End.Value))
+	}
+	return b.String()
+}
+
+func quoteString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// EscapeIdent escapes an identifier, like a table or field name, for use in SurrealQL. Plain identifiers are left as
+// they are, any other is wrapped in ⟨⟩.
+func EscapeIdent(s string) string {
+	return escapeIdent(s)
+}
+
+// escapeIdent leaves plain identifiers as they are and wraps any other in ⟨⟩.
+func escapeIdent(s string) string {
+	if isIdent(s) {
+		return s
+	}
+	return "⟨" + strings.NewReplacer(`\`, `\\`, "⟩", `\⟩`).Replace(s) + "⟩"
+}
+
+func isIdent(s string) bool {
+	if s == "" || isDigit(s[0]) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || isDigit(b) || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// literalParser parses the subset of SurrealQL literals that may appear in record ids: strings, numbers, booleans,
+// NULL, NONE, UUIDs, record ids, arrays and objects of those.
+type literalParser struct {
+	s   string
+	pos int
+}
+
+func (p *literalParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid literal at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *literalParser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *literalParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *literalParser) skipSpace() {
+	for !p.done() && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *literalParser) consume(prefix string) bool {
+	if strings.HasPrefix(p.s[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (p *literalParser) ident() string {
+	start := p.pos
+	for !p.done() && isIdentByte(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// escaped reads an identifier wrapped in ⟨⟩ or backticks, the opening delimiter already consumed.
+func (p *literalParser) escaped(closing string) (string, error) {
+	var b strings.Builder
+	for !p.done() {
+		switch {
+		case p.consume(closing):
+			return b.String(), nil
+		case p.consume(`\`):
+			r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+			if size == 0 {
+				return "", p.errorf("unterminated escape")
+			}
+			b.WriteRune(r)
+			p.pos += size
+		default:
+			r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+			b.WriteRune(r)
+			p.pos += size
+		}
+	}
+	return "", p.errorf("missing closing %s", closing)
+}
+
+func (p *literalParser) quoted(quote byte) (string, error) {
+	var b strings.Builder
+	p.pos++
+	for !p.done() {
+		c := p.s[p.pos]
+		p.pos++
+		switch c {
+		case quote:
+			return b.String(), nil
+		case '\\':
+			if p.done() {
+				return "", p.errorf("unterminated escape")
+			}
+			switch e := p.s[p.pos]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(e)
+			}
+			p.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *literalParser) number() (any, error) {
+	start := p.pos
+	if p.peek() == '-' || p.peek() == '+' {
+		p.pos++
+	}
+	float := false
+scan:
+	for !p.done() {
+		c := p.s[p.pos]
+		switch {
+		case isDigit(c):
+		case c == '.' && !strings.HasPrefix(p.s[p.pos:], ".."):
+			float = true
+		case c == 'e' || c == 'E':
+			float = true
+			if next := p.pos + 1; next < len(p.s) && (p.s[next] == '-' || p.s[next] == '+') {
+				p.pos++
+			}
+		default:
+			break scan
+		}
+		p.pos++
+	}
+
+	// a number followed by letters is a decimal, e.g. 1.5dec, or a duration, e.g. 1h30m
+	end := p.pos
+	for !p.done() && (isIdentByte(p.s[p.pos]) || p.s[p.pos] >= utf8.RuneSelf) {
+		p.pos++
+	}
+	switch suffix := p.s[end:p.pos]; {
+	case suffix == "dec":
+		return ParseDecimal(p.s[start:p.pos])
+	case suffix != "":
+		duration, err := ParseDuration(p.s[start:p.pos])
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.s[start:p.pos])
+		}
+		return duration, nil
+	}
+
+	text := p.s[start:p.pos]
+	if !float {
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %q", text)
+	}
+	return f, nil
+}
+
+func (p *literalParser) value() (any, error) {
+	p.skipSpace()
+
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		return p.quoted(c)
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return p.object()
+	case c == '-' || c == '+' || isDigit(c):
+		return p.number()
+	case c == 'u' && p.pos+1 < len(p.s) && (p.s[p.pos+1] == '\'' || p.s[p.pos+1] == '"'):
+		p.pos++
+		s, err := p.quoted(p.peek())
+		if err != nil {
+			return nil, err
+		}
+		return ParseUUID(s)
+	case c == 'd' && p.pos+1 < len(p.s) && (p.s[p.pos+1] == '\'' || p.s[p.pos+1] == '"'):
+		p.pos++
+		s, err := p.quoted(p.peek())
+		if err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, p.errorf("invalid datetime %q", s)
+		}
+		return Datetime{t}, nil
+	case isIdentByte(c) || strings.HasPrefix(p.s[p.pos:], "⟨") || c == '`':
+		start := p.pos
+		table, err := p.table()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() == ':' {
+			p.pos = start
+			return p.recordID()
+		}
+		switch strings.ToUpper(table) {
+		case "TRUE":
+			return true, nil
+		case "FALSE":
+			return false, nil
+		case "NULL":
+			return nil, nil
+		case "NONE":
+			return None, nil
+		}
+		return nil, p.errorf("unexpected identifier %q", table)
+	default:
+		return nil, p.errorf("unexpected %q", c)
+	}
+}
+
+func (p *literalParser) array() ([]any, error) {
+	p.pos++
+	items := []any{}
+	for {
+		p.skipSpace()
+		if p.consume("]") {
+			return items, nil
+		}
+
+		item, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		p.skipSpace()
+		if !p.consume(",") && p.peek() != ']' {
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+}
+
+func (p *literalParser) object() (map[string]any, error) {
+	p.pos++
+	fields := map[string]any{}
+	for {
+		p.skipSpace()
+		if p.consume("}") {
+			return fields, nil
+		}
+
+		var key string
+		var err error
+		switch c := p.peek(); {
+		case c == '\'' || c == '"':
+			key, err = p.quoted(c)
+		default:
+			key, err = p.table()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		if !p.consume(":") {
+			return nil, p.errorf("expected :")
+		}
+
+		if fields[key], err = p.value(); err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		if !p.consume(",") && p.peek() != '}' {
+			return nil, p.errorf("expected , or }")
+		}
+	}
+}
+
+// table reads a plain or escaped identifier.
+func (p *literalParser) table() (string, error) {
+	switch {
+	case p.consume("⟨"):
+		return p.escaped("⟩")
+	case p.consume("`"):
+		return p.escaped("`")
+	}
+
+	ident := p.ident()
+	if ident == "" {
+		return "", p.errorf("expected identifier")
+	}
+	return ident, nil
+}
+
+func (p *literalParser) recordID() (RecordID, error) {
+	table, err := p.table()
+	if err != nil {
+		return RecordID{}, err
+	}
+	if !p.consume(":") {
+		return RecordID{}, p.errorf("expected :")
+	}
+
+	var begin any
+	if !strings.HasPrefix(p.s[p.pos:], "..") {
+		if begin, err = p.id(); err != nil {
+			return RecordID{}, err
+		}
+	}
+
+	beginExcluded := p.consume(">")
+	if !p.consume("..") {
+		if beginExcluded {
+			return RecordID{}, p.errorf("expected ..")
+		}
+		return RecordID{Table: table, ID: begin}, nil
+	}
+
+	var r Range
+	if begin != nil {
+		r.Begin = &Bound{Value: begin, Inclusive: !beginExcluded}
+	}
+	endIncluded := p.consume("=")
+	if !p.done() && strings.IndexByte(",]}) \t\r\n", p.peek()) < 0 {
+		end, err := p.id()
+		if err != nil {
+			return RecordID{}, err
+		}
+		r.End = &Bound{Value: end, Inclusive: endIncluded}
+	}
+
+	return RecordID{Table: table, ID: r}, nil
+}
+
+// id reads the id part of a record id.
+func (p *literalParser) id() (any, error) {
+	switch c := p.peek(); {
+	case strings.HasPrefix(p.s[p.pos:], "⟨"):
+		p.pos += len("⟨")
+		return p.escaped("⟩")
+	case c == '`':
+		p.pos++
+		return p.escaped("`")
+	case c == '[', c == '{', c == '\'', c == '"':
+		return p.value()
+	case c == 'u' && p.pos+1 < len(p.s) && (p.s[p.pos+1] == '\'' || p.s[p.pos+1] == '"'):
+		return p.value()
+	case c == '-' || c == '+':
+		return p.number()
+	case isIdentByte(c):
+		ident := p.ident()
+		// numeric ids are numbers, unless followed by letters
+		if n, err := strconv.ParseInt(ident, 10, 64); err == nil {
+			return n, nil
+		}
+		return ident, nil
+	default:
+		return nil, p.errorf("expected record id")
+	}
+}
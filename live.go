@@ -0,0 +1,96 @@
+package surreal
+
+import (
+	"context"
+	"fmt"
+	"github.com/terawatthour/surreal-go/rpc"
+)
+
+// LiveEvent is a live notification decoded for records of type T.
+type LiveEvent[T any] struct {
+	// QueryID is the id of the live query the event belongs to.
+	QueryID string
+	Action  rpc.Action
+	// RecordID is the id of the record that changed, zero if the server did not tell.
+	RecordID RecordID
+	// Record is the record after the change, or before it for Delete. Left zero for live queries started with diff set.
+	Record T
+	// Diff holds the JSON Patch operations that turn the previous version of the record into the current one, with
+	// their values decoded. Only set for live queries started with diff set.
+	Diff []Diff
+}
+
+// DecodeLiveEvent decodes a notification of a live query started with or without diff into a LiveEvent.
+func DecodeLiveEvent[T any](db *DB, notification rpc.LiveNotification, diff bool) (LiveEvent[T], error) {
+	return decodeLiveEvent[T](db.codec, notification, diff)
+}
+
+func decodeLiveEvent[T any](c codec, notification rpc.LiveNotification, diff bool) (LiveEvent[T], error) {
+	event := LiveEvent[T]{
+		QueryID: notification.ID,
+		Action:  notification.Action,
+	}
+
+	if c.shape(notification.Record) != shapeNull {
+		if err := c.unmarshal(notification.Record, &event.RecordID); err != nil {
+			return event, fmt.Errorf("invalid record id: %s", err)
+		}
+	}
+
+	switch shape := c.shape(notification.Result); {
+	case shape == shapeNull:
+	case shape == shapeOther && notification.Action == rpc.Delete:
+		// servers before 2.0 only send the id of deleted records
+		if err := c.unmarshal(notification.Result, &event.RecordID); err != nil {
+			return event, fmt.Errorf("invalid record id: %s", err)
+		}
+	case diff:
+		if err := c.unmarshal(notification.Result, &event.Diff); err != nil {
+			return event, fmt.Errorf("failed to decode diff: %s", err)
+		}
+	default:
+		if err := decode(c, notification.Result, &event.Record); err != nil {
+			return event, fmt.Errorf("failed to decode record: %s", err)
+		}
+		if event.RecordID.IsZero() {
+			var record struct {
+				ID RecordID `json:"id"`
+			}
+			_ = c.unmarshal(notification.Result, &record)
+			event.RecordID = record.ID
+		}
+	}
+
+	return event, nil
+}
+
+// LiveSubscription is a Subscription delivering notifications decoded into LiveEvents for records of type T. A
+// notification that cannot be decoded ends the subscription, Err then reports why.
+type LiveSubscription[T any] struct {
+	*subscriber[LiveEvent[T]]
+}
+
+// SubscribeAs starts a live query on a table and returns a LiveSubscription delivering its notifications decoded.
+func SubscribeAs[T any](db *DB, table string, options *SubscriptionOptions) (*LiveSubscription[T], error) {
+	return SubscribeAsContext[T](context.Background(), db, table, options)
+}
+
+// SubscribeAsContext is like SubscribeAs, but the request is abandoned once ctx is done. Cancelling ctx after the live
+// query has been started does not close the subscription, use Close for that.
+func SubscribeAsContext[T any](ctx context.Context, db *DB, table string, options *SubscriptionOptions) (*LiveSubscription[T], error) {
+	diff := options != nil && options.Diff
+
+	s, err := subscribe(ctx, db, table, options, func(notification rpc.LiveNotification) (LiveEvent[T], error) {
+		return decodeLiveEvent[T](db.codec, notification, diff)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &LiveSubscription[T]{s}, nil
+}
+
+// Events returns the channel events are delivered over. It is closed once the subscription ends, after which Err
+// reports why.
+func (s *LiveSubscription[T]) Events() <-chan LiveEvent[T] {
+	return s.ch
+}
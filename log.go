@@ -0,0 +1,28 @@
+package surreal
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logger returns the logger events are reported to: Logger if set, the default logger if only Verbose is set, and
+// one discarding everything otherwise.
+func (o *Options) logger() *slog.Logger {
+	switch {
+	case o.Logger != nil:
+		return o.Logger
+	case o.Verbose:
+		return slog.Default()
+	default:
+		return discardLogger
+	}
+}
+
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
@@ -0,0 +1,132 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// Migration is a versioned change to the schema, read from a pair of files: <version>_<name>.up.surql, run to apply
+// it, and optionally <version>_<name>.down.surql, run to revert it. A file named <version>_<name>.surql is an up
+// script without a down one.
+type Migration struct {
+	// Version is the leading number of the file names, e.g. 0001 or 20240102150405. Migrations are applied in
+	// ascending numeric order of their versions.
+	Version string
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum returns the SHA-256 of the up script, recorded when the migration is applied so that later edits to it
+// can be detected.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads the migrations in the root directory of fsys, e.g. an embed.FS or os.DirFS("migrations"), ordered by
+// version. Files not ending in .surql are ignored. Two migrations whose versions are equal as numbers, like 1 and 01,
+// are rejected, as is an up script that is empty.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	// keyed by the numeric value of the version, so that 1 and 01 are told to clash
+	type files struct {
+		migration *Migration
+		up, down  bool
+	}
+	byVersion := map[string]*files{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".surql") {
+			continue
+		}
+
+		version, name, direction, err := parseFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		key := versionKey(version)
+		loaded, ok := byVersion[key]
+		if !ok {
+			loaded = &files{migration: &Migration{Version: version, Name: name}}
+			byVersion[key] = loaded
+		} else if migration := loaded.migration; migration.Version != version || migration.Name != name {
+			return nil, fmt.Errorf("migrations %s_%s and %s_%s share a version", migration.Version, migration.Name, version, name)
+		}
+
+		script, seen := &loaded.migration.Up, &loaded.up
+		if direction == "down" {
+			script, seen = &loaded.migration.Down, &loaded.down
+		}
+		if *seen {
+			return nil, fmt.Errorf("migration %s_%s has more than one %s script", version, name, direction)
+		}
+		*script, *seen = string(content), true
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, loaded := range byVersion {
+		migration := loaded.migration
+		if !loaded.up {
+			return nil, fmt.Errorf("migration %s_%s has no up script", migration.Version, migration.Name)
+		}
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("migration %s_%s has an empty up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(a, b int) bool {
+		return versionLess(migrations[a].Version, migrations[b].Version)
+	})
+
+	return migrations, nil
+}
+
+// parseFileName splits a file name like 0001_create_users.up.surql into its version, name and direction.
+func parseFileName(file string) (version, name, direction string, err error) {
+	base := strings.TrimSuffix(file, ".surql")
+	direction = "up"
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		base = strings.TrimSuffix(base, ".up")
+	case strings.HasSuffix(base, ".down"):
+		base = strings.TrimSuffix(base, ".down")
+		direction = "down"
+	}
+
+	end := 0
+	for end < len(base) && '0' <= base[end] && base[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return "", "", "", fmt.Errorf("migration %s does not start with a version number", file)
+	}
+
+	return base[:end], strings.TrimLeft(base[end:], "_-"), direction, nil
+}
+
+// versionKey strips the leading zeros of a version, so that versions equal as numbers have the same key.
+func versionKey(version string) string {
+	return strings.TrimLeft(version, "0")
+}
+
+// versionLess compares versions as numbers, which may be too large to parse.
+func versionLess(a, b string) bool {
+	a, b = versionKey(a), versionKey(b)
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
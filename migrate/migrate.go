@@ -0,0 +1,305 @@
+// Package migrate evolves the schema of a SurrealDB database with versioned .surql migrations.
+//
+//	//go:embed migrations/*.surql
+//	var files embed.FS
+//
+//	migrations, _ := fs.Sub(files, "migrations")
+//	migrator, err := migrate.New(db, migrations, nil)
+//	if err != nil {
+//		return err
+//	}
+//	applied, err := migrator.Up()
+//
+// Applied migrations are recorded in the _migrations table, along with the checksum of their up script. Each
+// migration runs in a transaction of its own together with the statement recording it, so that a failing migration
+// leaves neither changes nor a record behind. Scripts must therefore not begin or commit transactions themselves.
+//
+// While migrating, a lock record keeps other runners from migrating the same database concurrently.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/terawatthour/surreal-go"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTable is the table applied migrations are recorded in unless Options.Table is set.
+const DefaultTable = "_migrations"
+
+var (
+	// ErrLocked is returned when another runner holds the lock, or a runner failed without releasing it, in which
+	// case Unlock releases it.
+	ErrLocked = errors.New("migrations are locked by another runner")
+	// ErrChecksumMismatch is returned when the up script of an applied migration has been edited since.
+	ErrChecksumMismatch = errors.New("applied migration has been edited")
+	// ErrUnknownMigration is returned when a migration has been applied that is not among the files.
+	ErrUnknownMigration = errors.New("applied migration is missing")
+)
+
+// The statements recording that a migration has been applied or reverted, run along with its script.
+const (
+	recordApplied = "CREATE type::thing($table, $version) " +
+		"SET version = $version, name = $name, checksum = $checksum, applied_at = time::now()"
+	recordReverted = "DELETE type::thing($table, $version)"
+)
+
+// Options configure a Migrator.
+type Options struct {
+	// Table is the table applied migrations are recorded in, DefaultTable if empty. The lock record is kept in the
+	// table of the same name followed by _lock.
+	Table string
+
+	// DryRun, if set, receives the statements that would be run instead of them being run. The database is still
+	// read to tell which migrations are pending.
+	DryRun io.Writer
+}
+
+func (o *Options) table() string {
+	if o.Table == "" {
+		return DefaultTable
+	}
+	return o.Table
+}
+
+// Migrator applies and reverts migrations.
+type Migrator struct {
+	db         *surreal.DB
+	migrations []Migration
+	options    *Options
+}
+
+// Status tells whether a migration has been applied.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// applied is how an applied migration is recorded.
+type applied struct {
+	Version   string           `json:"version"`
+	Name      string           `json:"name"`
+	Checksum  string           `json:"checksum"`
+	AppliedAt surreal.Datetime `json:"applied_at"`
+}
+
+// New creates a Migrator for the migrations in the root directory of fsys, see Load.
+func New(db *surreal.DB, fsys fs.FS, options *Options) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithMigrations(db, migrations, options), nil
+}
+
+// NewWithMigrations creates a Migrator for migrations ordered by version, e.g. built in code rather than read from
+// files.
+func NewWithMigrations(db *surreal.DB, migrations []Migration, options *Options) *Migrator {
+	if options == nil {
+		options = &Options{}
+	}
+	return &Migrator{db: db, migrations: migrations, options: options}
+}
+
+// Status reports, for each migration, whether it has been applied. It fails with ErrChecksumMismatch or
+// ErrUnknownMigration if the applied migrations do not match the files.
+func (m *Migrator) Status() ([]Status, error) {
+	return m.StatusContext(context.Background())
+}
+
+// StatusContext is like Status, but the query is abandoned once ctx is done.
+func (m *Migrator) StatusContext(ctx context.Context) ([]Status, error) {
+	records, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i].Migration = migration
+		record, ok := records[migration.Version]
+		if !ok {
+			continue
+		}
+		if record.Checksum != migration.Checksum() {
+			return nil, fmt.Errorf("%w: %s_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+		statuses[i].Applied = true
+		statuses[i].AppliedAt = record.AppliedAt.Time
+		delete(records, migration.Version)
+	}
+
+	if len(records) > 0 {
+		var unknown []string
+		for _, record := range records {
+			unknown = append(unknown, record.Version+"_"+record.Name)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMigration, strings.Join(unknown, ", "))
+	}
+
+	return statuses, nil
+}
+
+// Up applies the pending migrations in order and returns them. It stops at the first failing migration, returning
+// the ones applied before it along with the error.
+func (m *Migrator) Up() ([]Migration, error) {
+	return m.UpContext(context.Background())
+}
+
+// UpContext is like Up, but the migration in progress is abandoned once ctx is done.
+func (m *Migrator) UpContext(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func() error {
+		statuses, err := m.StatusContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			if status.Applied {
+				continue
+			}
+			if err := m.run(ctx, status.Migration, status.Up, recordApplied); err != nil {
+				return fmt.Errorf("failed to apply migration %s_%s: %w", status.Version, status.Name, err)
+			}
+			done = append(done, status.Migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the last steps applied migrations, latest first, and returns them. It fails before reverting anything
+// if one of them has no down script.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	return m.DownContext(context.Background(), steps)
+}
+
+// DownContext is like Down, but the migration in progress is abandoned once ctx is done.
+func (m *Migrator) DownContext(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func() error {
+		statuses, err := m.StatusContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		var revert []Migration
+		for i := len(statuses) - 1; i >= 0 && len(revert) < steps; i-- {
+			if !statuses[i].Applied {
+				continue
+			}
+			if statuses[i].Down == "" {
+				return fmt.Errorf("migration %s_%s has no down script", statuses[i].Version, statuses[i].Name)
+			}
+			revert = append(revert, statuses[i].Migration)
+		}
+
+		for _, migration := range revert {
+			if err := m.run(ctx, migration, migration.Down, recordReverted); err != nil {
+				return fmt.Errorf("failed to revert migration %s_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Unlock releases the lock whoever holds it, e.g. after a runner was killed while migrating.
+func (m *Migrator) Unlock() error {
+	return m.UnlockContext(context.Background())
+}
+
+// UnlockContext is like Unlock, but the query is abandoned once ctx is done.
+func (m *Migrator) UnlockContext(ctx context.Context) error {
+	return m.db.QueryContext(ctx, "DELETE type::thing($lock, 'lock')", surreal.Map{"lock": m.options.table() + "_lock"})
+}
+
+// run runs script and record, the statement recording it, in one transaction.
+func (m *Migrator) run(ctx context.Context, migration Migration, script, record string) error {
+	vars := surreal.Map{
+		"table":    m.options.table(),
+		"version":  migration.Version,
+		"name":     migration.Name,
+		"checksum": migration.Checksum(),
+	}
+
+	if m.options.DryRun != nil {
+		_, err := fmt.Fprintf(m.options.DryRun, "-- %s_%s\nBEGIN TRANSACTION;\n%s\n%s;\nCOMMIT TRANSACTION;\n\n",
+			migration.Version, migration.Name, script, bindVars(record, vars))
+		return err
+	}
+
+	return m.db.Transaction(ctx, func(tx *surreal.Tx) error {
+		tx.Query(script, nil)
+		tx.Query(record, vars)
+		return nil
+	})
+}
+
+// bindVars writes the values of vars into record, for dry runs to print statements that can be run as they are.
+func bindVars(record string, vars surreal.Map) string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	// longer names first, so that $name is not replaced within $names
+	sort.Slice(names, func(a, b int) bool { return len(names[a]) > len(names[b]) })
+
+	for _, name := range names {
+		record = strings.ReplaceAll(record, "$"+name, strconv.Quote(vars[name].(string)))
+	}
+	return record
+}
+
+// applied returns the applied migrations by version.
+func (m *Migrator) applied(ctx context.Context) (map[string]applied, error) {
+	var records []applied
+	err := m.db.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM type::table($table)",
+		surreal.Map{"table": m.options.table()}, &records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	byVersion := make(map[string]applied, len(records))
+	for _, record := range records {
+		byVersion[record.Version] = record
+	}
+	return byVersion, nil
+}
+
+// locked calls fn holding the lock, unless this is a dry run.
+func (m *Migrator) locked(ctx context.Context, fn func() error) error {
+	if m.options.DryRun != nil {
+		return fn()
+	}
+
+	holder, _ := os.Hostname()
+	vars := surreal.Map{"lock": m.options.table() + "_lock", "holder": fmt.Sprintf("%s:%d", holder, os.Getpid())}
+	err := m.db.QueryContext(ctx, "CREATE type::thing($lock, 'lock') SET holder = $holder, acquired_at = time::now()", vars)
+	if errors.Is(err, surreal.ErrAlreadyExists) {
+		return ErrLocked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+
+	err = fn()
+
+	// the lock is released even if ctx is done, so that the next runner does not find it taken
+	unlock := m.db.QueryContext(context.WithoutCancel(ctx), "DELETE type::thing($lock, 'lock') WHERE holder = $holder", vars)
+	if err == nil && unlock != nil {
+		err = fmt.Errorf("failed to unlock migrations: %w", unlock)
+	}
+	return err
+}
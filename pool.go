@@ -0,0 +1,375 @@
+package surreal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/terawatthour/surreal-go/rpc"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultPoolSize                = 4
+	DefaultPoolHealthCheckInterval = 30 * time.Second
+)
+
+type PoolOptions struct {
+	// Size is the number of connections the pool keeps open. Defaults to 4.
+	Size int
+
+	// HealthCheckInterval is how often members that have been idle since the last check are pinged. Members failing
+	// the ping are passed over until they answer one again. Defaults to 30 seconds.
+	HealthCheckInterval time.Duration
+}
+
+func (o *PoolOptions) size() int {
+	if o.Size <= 0 {
+		return DefaultPoolSize
+	}
+	return o.Size
+}
+
+func (o *PoolOptions) healthCheckInterval() time.Duration {
+	if o.HealthCheckInterval == 0 {
+		return DefaultPoolHealthCheckInterval
+	}
+	return o.HealthCheckInterval
+}
+
+// Pool is a DB spreading its calls over several connections to the same endpoint, each call going to the connection
+// with the fewest calls in flight. It has the same methods as DB, and its embedded DB can be passed wherever a *DB is
+// expected.
+//
+// Use, Let, Unset, Authenticate and Invalidate are applied to every connection, as is the token obtained with SignIn
+// or SignUp. Live queries stay on the connection they were started on.
+type Pool struct {
+	*DB
+	pool *poolConnection
+}
+
+// ConnectPool opens PoolOptions.Size connections to connectionUrl. All connections share options.
+func ConnectPool(connectionUrl string, options *Options) (*Pool, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	pool := &poolConnection{
+		options: options,
+		codec:   options.Encoding.codec(),
+		lives:   make(map[string]*poolMember),
+		done:    make(chan struct{}),
+	}
+
+	for range options.PoolOptions.size() {
+		conn, err := establishConnection(connectionUrl, options)
+		if err != nil {
+			_ = pool.Close()
+			return nil, err
+		}
+		go conn.Run()
+
+		member := &poolMember{conn: conn}
+		member.healthy.Store(true)
+		pool.members = append(pool.members, member)
+	}
+
+	go pool.Run()
+
+	return &Pool{
+		DB: &DB{
+			conn:    intercept(pool, options.Interceptors),
+			options: options,
+			codec:   pool.codec,
+		},
+		pool: pool,
+	}, nil
+}
+
+// Size returns the number of connections in the pool.
+func (p *Pool) Size() int {
+	return len(p.pool.members)
+}
+
+// Healthy returns the number of connections that answered the last health check.
+func (p *Pool) Healthy() int {
+	healthy := 0
+	for _, member := range p.pool.members {
+		if member.healthy.Load() {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+type poolMember struct {
+	conn     Connection
+	inFlight atomic.Int64
+	healthy  atomic.Bool
+	// lastUsed is the time the last call on the member finished, in unix nanoseconds
+	lastUsed atomic.Int64
+}
+
+func (m *poolMember) send(ctx context.Context, method string, params []any) ([]byte, error) {
+	defer func() {
+		m.lastUsed.Store(time.Now().UnixNano())
+		m.inFlight.Add(-1)
+	}()
+
+	return m.conn.Send(ctx, method, params)
+}
+
+// poolConnection is the Connection behind a Pool, routing each call to one or all of its members.
+type poolConnection struct {
+	options *Options
+	codec   codec
+	members []*poolMember
+
+	// lock makes picking a member and counting the call against it a single step
+	lock sync.Mutex
+
+	// lives maps live query ids onto the member the query was started on
+	lives    map[string]*poolMember
+	liveLock sync.Mutex
+
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// Run health-checks the members until the pool is closed.
+func (p *poolConnection) Run() {
+	interval := p.options.PoolOptions.healthCheckInterval()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.checkHealth(interval)
+		}
+	}
+}
+
+// checkHealth pings the members without calls in flight that have not finished one within interval. Busy members
+// are assumed to be answering.
+func (p *poolConnection) checkHealth(interval time.Duration) {
+	var wg sync.WaitGroup
+	for _, member := range p.members {
+		if member.inFlight.Load() != 0 || time.Since(time.Unix(0, member.lastUsed.Load())) < interval {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			defer cancel()
+
+			_, err := member.conn.Send(ctx, "ping", []any{})
+			if err != nil {
+				p.options.logger().Warn("pool member failed health check", "error", err)
+			}
+			member.healthy.Store(err == nil)
+		}()
+	}
+	wg.Wait()
+}
+
+// acquire picks the healthy member with the fewest calls in flight, falling back to all members if none is healthy,
+// and counts a call against it. The call must be made with poolMember.send.
+func (p *poolConnection) acquire() *poolMember {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var picked *poolMember
+	for _, healthy := range []bool{true, false} {
+		for _, member := range p.members {
+			if healthy && !member.healthy.Load() {
+				continue
+			}
+			if picked == nil || member.inFlight.Load() < picked.inFlight.Load() {
+				picked = member
+			}
+		}
+		if picked != nil {
+			break
+		}
+	}
+
+	picked.inFlight.Add(1)
+	return picked
+}
+
+func (p *poolConnection) Send(ctx context.Context, method string, params []any) ([]byte, error) {
+	select {
+	case <-p.done:
+		return nil, ErrClosed
+	default:
+	}
+
+	switch method {
+	case "use", "let", "unset", "authenticate", "invalidate":
+		return p.broadcast(ctx, method, params, nil)
+	case "signin", "signup":
+		member := p.acquire()
+		result, err := member.send(ctx, method, params)
+		if err != nil {
+			return nil, err
+		}
+
+		// the other members join the session the token belongs to
+		if _, err := p.broadcast(ctx, "authenticate", []any{decodeToken(p.codec, result)}, member); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case "live":
+		member := p.acquire()
+		result, err := member.send(ctx, method, params)
+		if err != nil {
+			return nil, err
+		}
+
+		if id, err := decodeString(p.codec, result); err == nil {
+			p.liveLock.Lock()
+			p.lives[id] = member
+			p.liveLock.Unlock()
+		}
+		return result, nil
+	case "kill":
+		member := p.liveMember(params)
+		if member == nil {
+			member = p.acquire()
+		} else {
+			member.inFlight.Add(1)
+		}
+
+		result, err := member.send(ctx, method, params)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(params) == 1 {
+			p.liveLock.Lock()
+			delete(p.lives, fmt.Sprintf("%v", params[0]))
+			p.liveLock.Unlock()
+		}
+		return result, nil
+	default:
+		return p.acquire().send(ctx, method, params)
+	}
+}
+
+// pipeline splits the calls of a batch evenly over the healthy members, each of which sends its share on its own.
+func (p *poolConnection) pipeline(ctx context.Context, calls []*pendingCall) {
+	var members []*poolMember
+	for _, member := range p.members {
+		if member.healthy.Load() {
+			members = append(members, member)
+		}
+	}
+	if len(members) == 0 {
+		members = p.members
+	}
+
+	share := (len(calls) + len(members) - 1) / len(members)
+	for _, member := range members {
+		if len(calls) == 0 {
+			return
+		}
+		part := calls[:min(share, len(calls))]
+		calls = calls[len(part):]
+
+		// the calls are counted against the member until they are resolved
+		counted := make([]*pendingCall, len(part))
+		for i, call := range part {
+			counted[i] = &pendingCall{method: call.method, params: call.params, resolve: func(result []byte, err error) {
+				member.lastUsed.Store(time.Now().UnixNano())
+				member.inFlight.Add(-1)
+				call.resolve(result, err)
+			}}
+		}
+		member.inFlight.Add(int64(len(counted)))
+
+		go func() {
+			if conn, ok := member.conn.(pipeliner); ok {
+				conn.pipeline(ctx, counted)
+				return
+			}
+			for _, call := range counted {
+				call.resolve(member.conn.Send(ctx, call.method, call.params))
+			}
+		}()
+	}
+}
+
+// broadcast sends a call to every member except skip, returning the first result.
+func (p *poolConnection) broadcast(ctx context.Context, method string, params []any, skip *poolMember) ([]byte, error) {
+	results := make([][]byte, len(p.members))
+	errs := make([]error, len(p.members))
+
+	var wg sync.WaitGroup
+	for i, member := range p.members {
+		if member == skip {
+			continue
+		}
+
+		wg.Add(1)
+		member.inFlight.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = member.send(ctx, method, params)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result != nil {
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p *poolConnection) liveMember(params []any) *poolMember {
+	if len(params) != 1 {
+		return nil
+	}
+
+	p.liveLock.Lock()
+	defer p.liveLock.Unlock()
+
+	return p.lives[fmt.Sprintf("%v", params[0])]
+}
+
+func (p *poolConnection) RegisterLiveCallback(id string, callback func(notification rpc.LiveNotification)) {
+	if member := p.liveMember([]any{id}); member != nil {
+		member.conn.RegisterLiveCallback(id, callback)
+	}
+}
+
+// closed returns a channel closed once the pool has been closed.
+func (p *poolConnection) closed() <-chan struct{} {
+	return p.done
+}
+
+// Close closes all members of the pool.
+func (p *poolConnection) Close() error {
+	var errs []error
+	p.doneOnce.Do(func() {
+		close(p.done)
+		for _, member := range p.members {
+			if err := member.conn.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
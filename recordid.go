@@ -0,0 +1,175 @@
+package surreal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RecordID identifies a single record, e.g. `article:1` is RecordID{Table: "article", ID: int64(1)}. ID may be a
+// string, a number, an array, an object or a Range of ids.
+//
+// RecordID is accepted wherever DB methods take a record or table. It is sent as a string with EncodingJSON and as a
+// tagged value with EncodingCBOR.
+//
+// Compare record ids with Equal. The == operator panics for array and object ids, as slices and maps are not
+// comparable, and tells apart numeric ids decoded into different types.
+type RecordID struct {
+	Table string
+	ID    any
+}
+
+// NewRecordID creates a record id in the given table.
+func NewRecordID(table string, id any) RecordID {
+	return RecordID{Table: table, ID: id}
+}
+
+// ParseRecordID parses a record id written in SurrealQL, e.g. `article:1`, `article:⟨hello world⟩`,
+// `temperature:['London', d'2024-01-01']` or `person:{ name: 'John' }`. Numeric ids parse into int64.
+func ParseRecordID(s string) (RecordID, error) {
+	p := &literalParser{s: s}
+
+	r, err := p.recordID()
+	if err != nil {
+		return RecordID{}, fmt.Errorf("invalid record id %q: %s", s, err)
+	}
+	if !p.done() {
+		return RecordID{}, fmt.Errorf("invalid record id %q: unexpected trailing %q", s, s[p.pos:])
+	}
+
+	return r, nil
+}
+
+// MustParseRecordID is like ParseRecordID but panics if s is not a valid record id.
+func MustParseRecordID(s string) RecordID {
+	r, err := ParseRecordID(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// IsZero reports whether r is the zero RecordID.
+func (r RecordID) IsZero() bool {
+	return r.Table == "" && r.ID == nil
+}
+
+// Equal reports whether r and other identify the same record, i.e. whether they are written the same in SurrealQL.
+func (r RecordID) Equal(other RecordID) bool {
+	return r.String() == other.String()
+}
+
+// String formats the record id as SurrealQL, escaping the table and id where needed.
+func (r RecordID) String() string {
+	return escapeIdent(r.Table) + ":" + formatID(r.ID)
+}
+
+func formatID(id any) string {
+	switch id := id.(type) {
+	case string:
+		if id != "" && strings.Trim(id, "0123456789") != "" && strings.IndexFunc(id, func(r rune) bool {
+			return r > 127 || !isIdentByte(byte(r))
+		}) < 0 {
+			return id
+		}
+		return "⟨" + strings.NewReplacer(`\`, `\\`, "⟩", `\⟩`).Replace(id) + "⟩"
+	case float64:
+		// ids decoded from JSON numbers
+		if id == float64(int64(id)) {
+			return strconv.FormatInt(int64(id), 10)
+		}
+	}
+
+	return formatLiteral(id)
+}
+
+// MarshalJSON encodes the record id as a string, the zero RecordID as null.
+func (r RecordID) MarshalJSON() ([]byte, error) {
+	if r.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON decodes a record id from a string, or from an object with `tb` and `id` fields.
+func (r *RecordID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*r = RecordID{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := ParseRecordID(s)
+		if err != nil {
+			return err
+		}
+		*r = parsed
+		return nil
+	}
+
+	var thing struct {
+		Table string `json:"tb"`
+		ID    any    `json:"id"`
+	}
+	if err := json.Unmarshal(data, &thing); err != nil || thing.Table == "" {
+		return fmt.Errorf("invalid record id: %s", data)
+	}
+	*r = RecordID{Table: thing.Table, ID: thing.ID}
+
+	return nil
+}
+
+func (r RecordID) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagRecordID, []any{r.Table, r.ID})
+}
+
+func (r *RecordID) UnmarshalCBOR(data []byte) error {
+	var content any
+	if _, err := unmarshalTagged(data, &content, tagRecordID); err != nil {
+		return err
+	}
+
+	switch content := content.(type) {
+	case string:
+		parsed, err := ParseRecordID(content)
+		if err != nil {
+			return err
+		}
+		*r = parsed
+	case []any:
+		if len(content) != 2 {
+			return fmt.Errorf("invalid record id: %v", content)
+		}
+		table, ok := content[0].(string)
+		if !ok {
+			return fmt.Errorf("invalid record id: %v", content)
+		}
+		id := content[1]
+		// numeric ids decode the same as from a parsed string
+		if n, ok := id.(uint64); ok && n <= math.MaxInt64 {
+			id = int64(n)
+		}
+		*r = RecordID{Table: table, ID: id}
+	default:
+		return fmt.Errorf("invalid record id: %v", content)
+	}
+
+	return nil
+}
+
+// thing prepares a record or table argument of an RPC call. The server parses strings sent as JSON into record ids,
+// but takes strings sent as CBOR for table names, so record id strings are turned into a RecordID first.
+func (db *DB) thing(what any) any {
+	s, ok := what.(string)
+	if !ok || db.options.Encoding != EncodingCBOR || !strings.ContainsRune(s, ':') {
+		return what
+	}
+
+	if r, err := ParseRecordID(s); err == nil {
+		return r
+	}
+	return what
+}
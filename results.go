@@ -0,0 +1,121 @@
+package surreal
+
+import (
+	"context"
+	"fmt"
+	"github.com/terawatthour/surreal-go/rpc"
+	"time"
+)
+
+// QueryResults holds the outcome of every statement of a query, those that failed included.
+type QueryResults struct {
+	Statements []StatementResult
+
+	codec codec
+}
+
+// StatementResult is the outcome of a single statement of a query.
+type StatementResult struct {
+	// Status is "OK" for statements that succeeded and "ERR" for ones that failed.
+	Status string
+
+	// Time is how long the server took to run the statement.
+	Time time.Duration
+
+	// Result is the encoded result of the statement, or its error message if it failed.
+	Result rpc.RawMessage
+
+	// Err is the QueryError of a failed statement, nil otherwise.
+	Err error
+}
+
+// QueryResults sends a query like Query, but returns the results of all statements instead of decoding them, so that
+// the results of successful statements are kept when others fail. The returned error only reports a failure of the
+// query as a whole, statement failures are found in the results.
+func (db *DB) QueryResults(query string, vars Map) (*QueryResults, error) {
+	return db.QueryResultsContext(context.Background(), query, vars)
+}
+
+// QueryResultsContext is like QueryResults, but the request is abandoned once ctx is done.
+func (db *DB) QueryResultsContext(ctx context.Context, query string, vars Map) (*QueryResults, error) {
+	rawQueryResult, err := db.rawQuery(ctx, query, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.newQueryResults(rawQueryResult), nil
+}
+
+func (db *DB) newQueryResults(rawQueryResult rpc.RawResult) *QueryResults {
+	results := &QueryResults{
+		Statements: make([]StatementResult, len(rawQueryResult)),
+		codec:      db.codec,
+	}
+
+	for i, row := range rawQueryResult {
+		statement := StatementResult{
+			Status: "OK",
+			Time:   time.Duration(row.Time),
+			Result: row.Result,
+		}
+
+		if !row.OK {
+			message, err := decodeString(db.codec, row.Result)
+			if err != nil {
+				message = string(row.Result)
+			}
+			statement.Status = "ERR"
+			statement.Err = QueryError{i, message}
+		}
+
+		results.Statements[i] = statement
+	}
+
+	return results
+}
+
+// Len returns the number of statements.
+func (r *QueryResults) Len() int {
+	return len(r.Statements)
+}
+
+// Scan decodes the result of statement i into the destination. Returns the statement's error if it failed.
+func (r *QueryResults) Scan(i int, destination any) error {
+	if i < 0 || i >= len(r.Statements) {
+		return fmt.Errorf("no result for statement %d, the query had %d", i, len(r.Statements))
+	}
+
+	statement := r.Statements[i]
+	if statement.Err != nil {
+		return statement.Err
+	}
+	if err := decode(r.codec, statement.Result, destination); err != nil {
+		return fmt.Errorf("failed to decode result of %d query: %s", i, err)
+	}
+
+	return nil
+}
+
+// Err returns QueryErrors holding the errors of all failed statements, nil if none failed.
+func (r *QueryResults) Err() error {
+	var errors QueryErrors
+	for _, statement := range r.Statements {
+		if err, ok := statement.Err.(QueryError); ok {
+			errors = append(errors, err)
+		}
+	}
+
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+// Duration returns the total time the server took to run all statements.
+func (r *QueryResults) Duration() time.Duration {
+	var total time.Duration
+	for _, statement := range r.Statements {
+		total += statement.Time
+	}
+	return total
+}
@@ -3,15 +3,16 @@ package rpc
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/fxamacker/cbor/v2"
 	"time"
 )
 
 // Mirroring https://github.com/surrealdb/surrealdb.go implementation
 
 type Incoming struct {
-	ID     any             `json:"id" msgpack:"id"`
-	Error  *Error          `json:"error,omitempty"`
-	Result json.RawMessage `json:"result,omitempty"`
+	ID     any        `json:"id" msgpack:"id"`
+	Error  *Error     `json:"error,omitempty"`
+	Result RawMessage `json:"result,omitempty"`
 }
 
 type Outgoing struct {
@@ -21,10 +22,23 @@ type Outgoing struct {
 	Params []any  `json:"params,omitempty"`
 }
 
+// Action is the kind of change a live notification reports.
+type Action string
+
+const (
+	Create Action = "CREATE"
+	Update Action = "UPDATE"
+	Delete Action = "DELETE"
+	// Close is sent once the server has closed the live query on its own.
+	Close Action = "CLOSE"
+)
+
 type LiveNotification struct {
-	ID     string          `json:"id"`
-	Action string          `json:"action"`
-	Result json.RawMessage `json:"result"`
+	ID     string `json:"id"`
+	Action Action `json:"action"`
+	// Record is the id of the record the notification concerns. Only sent by servers from 2.0 on.
+	Record RawMessage `json:"record,omitempty"`
+	Result RawMessage `json:"result"`
 }
 
 type Error struct {
@@ -36,6 +50,33 @@ func (r *Error) Error() string {
 	return fmt.Sprintf("#%d: %s", r.Code, r.Message)
 }
 
+// RawMessage is a raw encoded value, in the encoding of the message it arrived in, either JSON or CBOR.
+type RawMessage []byte
+
+func (m RawMessage) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	return m, nil
+}
+
+func (m *RawMessage) UnmarshalJSON(data []byte) error {
+	*m = append((*m)[0:0], data...)
+	return nil
+}
+
+func (m RawMessage) MarshalCBOR() ([]byte, error) {
+	if m == nil {
+		return []byte{0xf6}, nil
+	}
+	return m, nil
+}
+
+func (m *RawMessage) UnmarshalCBOR(data []byte) error {
+	*m = append((*m)[0:0], data...)
+	return nil
+}
+
 type MappedBool bool
 
 func (b *MappedBool) UnmarshalJSON(data []byte) error {
@@ -43,6 +84,15 @@ func (b *MappedBool) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (b *MappedBool) UnmarshalCBOR(data []byte) error {
+	var s string
+	if err := cbor.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*b = s == "OK"
+	return nil
+}
+
 type MappedDuration time.Duration
 
 func (d *MappedDuration) UnmarshalJSON(data []byte) error {
@@ -50,6 +100,18 @@ func (d *MappedDuration) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &s); err != nil {
 		return err
 	}
+	return d.parse(s)
+}
+
+func (d *MappedDuration) UnmarshalCBOR(data []byte) error {
+	var s string
+	if err := cbor.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+func (d *MappedDuration) parse(s string) error {
 	duration, err := time.ParseDuration(s)
 	if err != nil {
 		return err
@@ -61,5 +123,5 @@ func (d *MappedDuration) UnmarshalJSON(data []byte) error {
 type RawResult []struct {
 	OK     MappedBool `json:"status"`
 	Time   MappedDuration
-	Result json.RawMessage
+	Result RawMessage
 }
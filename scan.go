@@ -1,13 +1,12 @@
 package surreal
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
 )
 
-// autoScan parses the raw data into the destination, raw data must always represent a JSON array.
-func autoScan(raw []byte, destination any) error {
+// autoScan parses the raw data into the destination, raw data must always represent an array or an object.
+func autoScan(c codec, raw []byte, destination any) error {
 	if reflect.TypeOf(destination).Kind() != reflect.Ptr {
 		return fmt.Errorf("expected pointer to destination")
 	}
@@ -16,17 +15,26 @@ func autoScan(raw []byte, destination any) error {
 		return nil
 	}
 
-	if raw[0] == '[' {
-		switch reflect.Indirect(reflect.ValueOf(destination)).Kind() {
-		case reflect.Slice, reflect.Array:
-			if err := json.Unmarshal(raw, destination); err != nil {
+	// geometries are slices and arrays too, but they stand for a single value
+	single := reflect.TypeOf(destination).Elem().Implements(reflect.TypeOf((*Geometry)(nil)).Elem())
+	if single && c.shape(raw) != shapeArray {
+		if err := decode(c, raw, destination); err != nil {
+			return fmt.Errorf("failed to decode result: %s", err)
+		}
+		return nil
+	}
+
+	if c.shape(raw) == shapeArray {
+		switch kind := reflect.Indirect(reflect.ValueOf(destination)).Kind(); {
+		case (kind == reflect.Slice || kind == reflect.Array) && !single:
+			if err := decode(c, raw, destination); err != nil {
 				return fmt.Errorf("failed to decode result: %s", err)
 			}
 		default:
 			sliceType := reflect.SliceOf(reflect.Indirect(reflect.ValueOf(destination)).Type())
 			value := reflect.New(reflect.MakeSlice(sliceType, 1, 1).Type()).Elem()
 
-			if err := json.Unmarshal(raw, value.Addr().Interface()); err != nil {
+			if err := decode(c, raw, value.Addr().Interface()); err != nil {
 				return fmt.Errorf("failed to decode result: %s", err)
 			}
 
@@ -34,19 +42,19 @@ func autoScan(raw []byte, destination any) error {
 				reflect.Indirect(reflect.ValueOf(destination)).Set(value.Index(0))
 			}
 		}
-	} else if raw[0] == '{' {
+	} else if c.shape(raw) == shapeObject {
 		switch reflect.Indirect(reflect.ValueOf(destination)).Kind() {
 		case reflect.Slice, reflect.Array:
 			sliceType := reflect.Indirect(reflect.ValueOf(destination)).Type().Elem()
 			instance := reflect.New(sliceType).Elem()
 
-			if err := json.Unmarshal(raw, instance.Addr().Interface()); err != nil {
+			if err := decode(c, raw, instance.Addr().Interface()); err != nil {
 				return fmt.Errorf("failed to decode result: %s", err)
 			}
 
 			reflect.Indirect(reflect.ValueOf(destination)).Set(reflect.Append(reflect.Indirect(reflect.ValueOf(destination)), instance))
 		default:
-			if err := json.Unmarshal(raw, destination); err != nil {
+			if err := decode(c, raw, destination); err != nil {
 				return fmt.Errorf("failed to decode result: %s", err)
 			}
 		}
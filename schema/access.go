@@ -0,0 +1,140 @@
+package schema
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"strings"
+)
+
+// Level is what an access method grants access to.
+type Level string
+
+const (
+	OnRoot      Level = "ROOT"
+	OnNamespace Level = "NAMESPACE"
+	OnDatabase  Level = "DATABASE"
+)
+
+// AccessStatement builds a DEFINE ACCESS statement. Access methods are either record ones, letting users sign up and
+// sign in as records of a table, see Record, or JWT ones, accepting tokens issued elsewhere, see JWT and JWKS.
+type AccessStatement struct {
+	define
+	name  string
+	level Level
+
+	record    bool
+	signup    string
+	signin    string
+	algorithm string
+	key       string
+	url       string
+
+	token   *surreal.Duration
+	session *surreal.Duration
+}
+
+// DefineAccess starts a DEFINE ACCESS statement.
+func DefineAccess(name string, level Level) *AccessStatement {
+	return &AccessStatement{name: name, level: level}
+}
+
+// IfNotExists leaves an existing access method as it is instead of failing.
+func (s *AccessStatement) IfNotExists() *AccessStatement {
+	s.ifNotExists = true
+	return s
+}
+
+// Overwrite replaces an existing access method's definition instead of failing.
+func (s *AccessStatement) Overwrite() *AccessStatement {
+	s.overwrite = true
+	return s
+}
+
+// Record makes the access method a record one, signing users up and in with the given expressions, either of which
+// may be left empty. The expressions see the variables passed to DB.SignUp and DB.SignIn, e.g.
+//
+//	CREATE user SET email = $email, pass = crypto::argon2::generate($pass)
+//	SELECT * FROM user WHERE email = $email AND crypto::argon2::compare(pass, $pass)
+func (s *AccessStatement) Record(signup, signin string) *AccessStatement {
+	s.record, s.signup, s.signin = true, signup, signin
+	return s
+}
+
+// JWT verifies tokens with the given algorithm, e.g. HS512 or RS256, and key. On a record access method it also
+// sets how the tokens it issues are signed.
+func (s *AccessStatement) JWT(algorithm, key string) *AccessStatement {
+	s.algorithm, s.key, s.url = algorithm, key, ""
+	return s
+}
+
+// JWKS verifies tokens with the keys published at url.
+func (s *AccessStatement) JWKS(url string) *AccessStatement {
+	s.url, s.algorithm, s.key = url, "", ""
+	return s
+}
+
+// Duration sets how long the tokens issued and the sessions started by the access method last, leaving the server's
+// default for whichever is zero.
+func (s *AccessStatement) Duration(token, session surreal.Duration) *AccessStatement {
+	s.token, s.session = nil, nil
+	if token != 0 {
+		s.token = &token
+	}
+	if session != 0 {
+		s.session = &session
+	}
+	return s
+}
+
+// Comment attaches a comment to the access method.
+func (s *AccessStatement) Comment(comment string) *AccessStatement {
+	s.comment = &comment
+	return s
+}
+
+func (s *AccessStatement) String() string {
+	var b strings.Builder
+	b.WriteString(s.head("ACCESS", ident(s.name)))
+	b.WriteString(" ON " + string(s.level))
+
+	jwt := ""
+	switch {
+	case s.url != "":
+		jwt = " URL " + quote(s.url)
+	case s.algorithm != "":
+		jwt = " ALGORITHM " + s.algorithm + " KEY " + quote(s.key)
+	}
+
+	if s.record {
+		b.WriteString(" TYPE RECORD")
+		if s.signup != "" {
+			b.WriteString(" SIGNUP (" + s.signup + ")")
+		}
+		if s.signin != "" {
+			b.WriteString(" SIGNIN (" + s.signin + ")")
+		}
+		if jwt != "" {
+			b.WriteString(" WITH JWT" + jwt)
+		}
+	} else {
+		b.WriteString(" TYPE JWT" + jwt)
+	}
+
+	var durations []string
+	if s.token != nil {
+		durations = append(durations, "FOR TOKEN "+s.token.String())
+	}
+	if s.session != nil {
+		durations = append(durations, "FOR SESSION "+s.session.String())
+	}
+	if len(durations) > 0 {
+		b.WriteString(" DURATION " + strings.Join(durations, ", "))
+	}
+	b.WriteString(s.tail())
+
+	return b.String()
+}
+
+// Build implements surreal.Statement.
+func (s *AccessStatement) Build() (string, surreal.Map, error) {
+	return s.String(), nil, nil
+}
@@ -0,0 +1,304 @@
+package schema
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"strings"
+)
+
+// NamespaceStatement builds a DEFINE NAMESPACE statement.
+type NamespaceStatement struct {
+	define
+	name string
+}
+
+// DefineNamespace starts a DEFINE NAMESPACE statement.
+func DefineNamespace(name string) *NamespaceStatement {
+	return &NamespaceStatement{name: name}
+}
+
+// IfNotExists leaves an existing namespace as it is instead of failing.
+func (s *NamespaceStatement) IfNotExists() *NamespaceStatement {
+	s.ifNotExists = true
+	return s
+}
+
+// Overwrite replaces an existing namespace's definition instead of failing.
+func (s *NamespaceStatement) Overwrite() *NamespaceStatement {
+	s.overwrite = true
+	return s
+}
+
+// Comment attaches a comment to the namespace.
+func (s *NamespaceStatement) Comment(comment string) *NamespaceStatement {
+	s.comment = &comment
+	return s
+}
+
+func (s *NamespaceStatement) String() string {
+	return s.head("NAMESPACE", ident(s.name)) + s.tail()
+}
+
+// Build implements surreal.Statement.
+func (s *NamespaceStatement) Build() (string, surreal.Map, error) {
+	return s.String(), nil, nil
+}
+
+// DatabaseStatement builds a DEFINE DATABASE statement.
+type DatabaseStatement struct {
+	define
+	name       string
+	changefeed *surreal.Duration
+}
+
+// DefineDatabase starts a DEFINE DATABASE statement, defining a database in the namespace in use.
+func DefineDatabase(name string) *DatabaseStatement {
+	return &DatabaseStatement{name: name}
+}
+
+// IfNotExists leaves an existing database as it is instead of failing.
+func (s *DatabaseStatement) IfNotExists() *DatabaseStatement {
+	s.ifNotExists = true
+	return s
+}
+
+// Overwrite replaces an existing database's definition instead of failing.
+func (s *DatabaseStatement) Overwrite() *DatabaseStatement {
+	s.overwrite = true
+	return s
+}
+
+// Changefeed keeps the changes made to the database for the given duration.
+func (s *DatabaseStatement) Changefeed(duration surreal.Duration) *DatabaseStatement {
+	s.changefeed = &duration
+	return s
+}
+
+// Comment attaches a comment to the database.
+func (s *DatabaseStatement) Comment(comment string) *DatabaseStatement {
+	s.comment = &comment
+	return s
+}
+
+func (s *DatabaseStatement) String() string {
+	statement := s.head("DATABASE", ident(s.name))
+	if s.changefeed != nil {
+		statement += " CHANGEFEED " + s.changefeed.String()
+	}
+	return statement + s.tail()
+}
+
+// Build implements surreal.Statement.
+func (s *DatabaseStatement) Build() (string, surreal.Map, error) {
+	return s.String(), nil, nil
+}
+
+// TableStatement builds a DEFINE TABLE statement.
+type TableStatement struct {
+	define
+	name        string
+	kind        string
+	in, out     []string
+	enforced    bool
+	drop        bool
+	schemafull  *bool
+	as          string
+	changefeed  *surreal.Duration
+	original    bool
+	permissions *Permissions
+}
+
+// DefineTable starts a DEFINE TABLE statement.
+func DefineTable(name string) *TableStatement {
+	return &TableStatement{name: name}
+}
+
+// IfNotExists leaves an existing table as it is instead of failing.
+func (s *TableStatement) IfNotExists() *TableStatement {
+	s.ifNotExists = true
+	return s
+}
+
+// Overwrite replaces an existing table's definition instead of failing.
+func (s *TableStatement) Overwrite() *TableStatement {
+	s.overwrite = true
+	return s
+}
+
+// Normal makes the table hold plain records only, not relations.
+func (s *TableStatement) Normal() *TableStatement {
+	s.kind = "NORMAL"
+	return s
+}
+
+// Relation makes the table hold relations from records of the in tables to records of the out tables, either of
+// which may be left empty to allow any table.
+func (s *TableStatement) Relation(in, out []string) *TableStatement {
+	s.kind, s.in, s.out = "RELATION", in, out
+	return s
+}
+
+// Enforced makes the records a relation links exist, rather than creating them on the fly.
+func (s *TableStatement) Enforced() *TableStatement {
+	s.enforced = true
+	return s
+}
+
+// Drop makes the table discard the records written to it, e.g. to only trigger events.
+func (s *TableStatement) Drop() *TableStatement {
+	s.drop = true
+	return s
+}
+
+// Schemafull makes the table reject fields that are not defined.
+func (s *TableStatement) Schemafull() *TableStatement {
+	schemafull := true
+	s.schemafull = &schemafull
+	return s
+}
+
+// Schemaless makes the table accept any field.
+func (s *TableStatement) Schemaless() *TableStatement {
+	schemafull := false
+	s.schemafull = &schemafull
+	return s
+}
+
+// As makes the table a view over the results of query, a SELECT statement.
+func (s *TableStatement) As(query string) *TableStatement {
+	s.as = query
+	return s
+}
+
+// Changefeed keeps the changes made to the table for the given duration, along with the original records if
+// includeOriginal is set.
+func (s *TableStatement) Changefeed(duration surreal.Duration, includeOriginal bool) *TableStatement {
+	s.changefeed, s.original = &duration, includeOriginal
+	return s
+}
+
+// Permissions sets who may select, create, update and delete records of the table.
+func (s *TableStatement) Permissions(permissions Permissions) *TableStatement {
+	s.permissions = &permissions
+	return s
+}
+
+// Comment attaches a comment to the table.
+func (s *TableStatement) Comment(comment string) *TableStatement {
+	s.comment = &comment
+	return s
+}
+
+func (s *TableStatement) String() string {
+	var b strings.Builder
+	b.WriteString(s.head("TABLE", ident(s.name)))
+
+	if s.kind != "" {
+		b.WriteString(" TYPE " + s.kind)
+		if len(s.in) > 0 {
+			b.WriteString(" IN " + idents(s.in, " | "))
+		}
+		if len(s.out) > 0 {
+			b.WriteString(" OUT " + idents(s.out, " | "))
+		}
+		if s.enforced {
+			b.WriteString(" ENFORCED")
+		}
+	}
+	if s.drop {
+		b.WriteString(" DROP")
+	}
+	if s.schemafull != nil {
+		if *s.schemafull {
+			b.WriteString(" SCHEMAFULL")
+		} else {
+			b.WriteString(" SCHEMALESS")
+		}
+	}
+	if s.as != "" {
+		b.WriteString(" AS " + s.as)
+	}
+	if s.changefeed != nil {
+		b.WriteString(" CHANGEFEED " + s.changefeed.String())
+		if s.original {
+			b.WriteString(" INCLUDE ORIGINAL")
+		}
+	}
+	b.WriteString(s.permissions.render(tableOperations...))
+	b.WriteString(s.tail())
+
+	return b.String()
+}
+
+// Build implements surreal.Statement.
+func (s *TableStatement) Build() (string, surreal.Map, error) {
+	return s.String(), nil, nil
+}
+
+// AnalyzerStatement builds a DEFINE ANALYZER statement, defining how text is split into terms by search indexes.
+type AnalyzerStatement struct {
+	define
+	name       string
+	function   string
+	tokenizers []string
+	filters    []string
+}
+
+// DefineAnalyzer starts a DEFINE ANALYZER statement.
+func DefineAnalyzer(name string) *AnalyzerStatement {
+	return &AnalyzerStatement{name: name}
+}
+
+// IfNotExists leaves an existing analyzer as it is instead of failing.
+func (s *AnalyzerStatement) IfNotExists() *AnalyzerStatement {
+	s.ifNotExists = true
+	return s
+}
+
+// Overwrite replaces an existing analyzer's definition instead of failing.
+func (s *AnalyzerStatement) Overwrite() *AnalyzerStatement {
+	s.overwrite = true
+	return s
+}
+
+// Function passes text through a custom function, e.g. fn::strip_html, before it is tokenized.
+func (s *AnalyzerStatement) Function(name string) *AnalyzerStatement {
+	s.function = name
+	return s
+}
+
+// Tokenizers splits text with the given tokenizers, e.g. blank, camel, class or punct.
+func (s *AnalyzerStatement) Tokenizers(tokenizers ...string) *AnalyzerStatement {
+	s.tokenizers = append(s.tokenizers, tokenizers...)
+	return s
+}
+
+// Filters transforms terms with the given filters, e.g. lowercase, ascii or snowball(english).
+func (s *AnalyzerStatement) Filters(filters ...string) *AnalyzerStatement {
+	s.filters = append(s.filters, filters...)
+	return s
+}
+
+// Comment attaches a comment to the analyzer.
+func (s *AnalyzerStatement) Comment(comment string) *AnalyzerStatement {
+	s.comment = &comment
+	return s
+}
+
+func (s *AnalyzerStatement) String() string {
+	statement := s.head("ANALYZER", ident(s.name))
+	if s.function != "" {
+		statement += " FUNCTION " + s.function
+	}
+	if len(s.tokenizers) > 0 {
+		statement += " TOKENIZERS " + strings.Join(s.tokenizers, ",")
+	}
+	if len(s.filters) > 0 {
+		statement += " FILTERS " + strings.Join(s.filters, ",")
+	}
+	return statement + s.tail()
+}
+
+// Build implements surreal.Statement.
+func (s *AnalyzerStatement) Build() (string, surreal.Map, error) {
+	return s.String(), nil, nil
+}
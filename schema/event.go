@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"strings"
+)
+
+// EventStatement builds a DEFINE EVENT statement, running statements whenever a record of a table changes.
+type EventStatement struct {
+	define
+	name       string
+	table      string
+	when       string
+	statements []string
+}
+
+// DefineEvent starts a DEFINE EVENT statement.
+func DefineEvent(name, table string) *EventStatement {
+	return &EventStatement{name: name, table: table}
+}
+
+// IfNotExists leaves an existing event as it is instead of failing.
+func (s *EventStatement) IfNotExists() *EventStatement {
+	s.ifNotExists = true
+	return s
+}
+
+// Overwrite replaces an existing event's definition instead of failing.
+func (s *EventStatement) Overwrite() *EventStatement {
+	s.overwrite = true
+	return s
+}
+
+// When only runs the event if condition, e.g. "$event = 'CREATE'" or "$before.email != $after.email", is true.
+func (s *EventStatement) When(condition string) *EventStatement {
+	s.when = condition
+	return s
+}
+
+// Then sets the statements the event runs, which may refer to $event, $before, $after and $value.
+func (s *EventStatement) Then(statements ...string) *EventStatement {
+	s.statements = append(s.statements, statements...)
+	return s
+}
+
+// Comment attaches a comment to the event.
+func (s *EventStatement) Comment(comment string) *EventStatement {
+	s.comment = &comment
+	return s
+}
+
+func (s *EventStatement) String() string {
+	statement := s.head("EVENT", ident(s.name)) + " ON TABLE " + ident(s.table)
+	if s.when != "" {
+		statement += " WHEN " + s.when
+	}
+	switch len(s.statements) {
+	case 0:
+	case 1:
+		statement += " THEN (" + s.statements[0] + ")"
+	default:
+		statement += " THEN { " + strings.Join(s.statements, "; ") + " }"
+	}
+	return statement + s.tail()
+}
+
+// Build implements surreal.Statement.
+func (s *EventStatement) Build() (string, surreal.Map, error) {
+	return s.String(), nil, nil
+}
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"strings"
+)
+
+// FieldStatement builds a DEFINE FIELD statement.
+type FieldStatement struct {
+	define
+	name          string
+	table         string
+	typ           Type
+	flexible      bool
+	defaultValue  string
+	defaultAlways bool
+	readonly      bool
+	value         string
+	assert        string
+	permissions   *Permissions
+}
+
+// DefineField starts a DEFINE FIELD statement, defining the field at path, e.g. title, address.city or tags[*], on
+// table.
+func DefineField(path, table string) *FieldStatement {
+	return &FieldStatement{name: path, table: table}
+}
+
+// IfNotExists leaves an existing field as it is instead of failing.
+func (s *FieldStatement) IfNotExists() *FieldStatement {
+	s.ifNotExists = true
+	return s
+}
+
+// Overwrite replaces an existing field's definition instead of failing.
+func (s *FieldStatement) Overwrite() *FieldStatement {
+	s.overwrite = true
+	return s
+}
+
+// Type restricts the values of the field to t.
+func (s *FieldStatement) Type(t Type) *FieldStatement {
+	s.typ = t
+	return s
+}
+
+// Flexible lets an object field of a schemafull table hold fields that are not defined.
+func (s *FieldStatement) Flexible() *FieldStatement {
+	s.flexible = true
+	return s
+}
+
+// Default sets the field to expression, e.g. "time::now()" or "'draft'", when it is left out.
+func (s *FieldStatement) Default(expression string) *FieldStatement {
+	s.defaultValue, s.defaultAlways = expression, false
+	return s
+}
+
+// DefaultAlways is like Default, but also applies when the field is set to NONE later on.
+func (s *FieldStatement) DefaultAlways(expression string) *FieldStatement {
+	s.defaultValue, s.defaultAlways = expression, true
+	return s
+}
+
+// Readonly keeps the field from being changed once the record is created.
+func (s *FieldStatement) Readonly() *FieldStatement {
+	s.readonly = true
+	return s
+}
+
+// Value sets the field to expression, e.g. "time::now()" or "string::lowercase($value)", whenever it is written.
+func (s *FieldStatement) Value(expression string) *FieldStatement {
+	s.value = expression
+	return s
+}
+
+// Assert rejects values for which condition, e.g. "string::is::email($value)", is not true.
+func (s *FieldStatement) Assert(condition string) *FieldStatement {
+	s.assert = condition
+	return s
+}
+
+// Permissions sets who may select, create and update the field.
+func (s *FieldStatement) Permissions(permissions Permissions) *FieldStatement {
+	s.permissions = &permissions
+	return s
+}
+
+// Comment attaches a comment to the field.
+func (s *FieldStatement) Comment(comment string) *FieldStatement {
+	s.comment = &comment
+	return s
+}
+
+func (s *FieldStatement) String() string {
+	var b strings.Builder
+	b.WriteString(s.head("FIELD", fieldName(s.name)))
+	b.WriteString(" ON TABLE " + ident(s.table))
+
+	if s.flexible {
+		b.WriteString(" FLEXIBLE")
+	}
+	if s.typ != "" {
+		b.WriteString(" TYPE " + string(s.typ))
+	}
+	if s.defaultValue != "" {
+		b.WriteString(" DEFAULT ")
+		if s.defaultAlways {
+			b.WriteString("ALWAYS ")
+		}
+		b.WriteString(s.defaultValue)
+	}
+	if s.readonly {
+		b.WriteString(" READONLY")
+	}
+	if s.value != "" {
+		b.WriteString(" VALUE " + s.value)
+	}
+	if s.assert != "" {
+		b.WriteString(" ASSERT " + s.assert)
+	}
+	b.WriteString(s.permissions.render(fieldOperations...))
+	b.WriteString(s.tail())
+
+	return b.String()
+}
+
+// Build implements surreal.Statement.
+func (s *FieldStatement) Build() (string, surreal.Map, error) {
+	return s.String(), nil, nil
+}
@@ -0,0 +1,155 @@
+package schema
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"strconv"
+	"strings"
+)
+
+// Distance is the metric vector indexes compare vectors with.
+type Distance string
+
+const (
+	DistanceChebyshev Distance = "CHEBYSHEV"
+	DistanceCosine    Distance = "COSINE"
+	DistanceEuclidean Distance = "EUCLIDEAN"
+	DistanceHamming   Distance = "HAMMING"
+	DistanceJaccard   Distance = "JACCARD"
+	DistanceManhattan Distance = "MANHATTAN"
+	DistancePearson   Distance = "PEARSON"
+)
+
+// IndexStatement builds a DEFINE INDEX statement.
+type IndexStatement struct {
+	define
+	name   string
+	table  string
+	fields []string
+
+	kind       string
+	analyzer   string
+	bm25       *[2]float64
+	highlights bool
+	dimension  int
+	distance   Distance
+	efc        int
+	m          int
+}
+
+// DefineIndex starts a DEFINE INDEX statement. Unless Unique, Search, MTree or HNSW is called the index is a plain
+// one, speeding up lookups of the fields.
+func DefineIndex(name, table string) *IndexStatement {
+	return &IndexStatement{name: name, table: table}
+}
+
+// IfNotExists leaves an existing index as it is instead of failing.
+func (s *IndexStatement) IfNotExists() *IndexStatement {
+	s.ifNotExists = true
+	return s
+}
+
+// Overwrite replaces an existing index's definition instead of failing.
+func (s *IndexStatement) Overwrite() *IndexStatement {
+	s.overwrite = true
+	return s
+}
+
+// Fields sets the fields, e.g. "email" or "address.city", the index covers.
+func (s *IndexStatement) Fields(fields ...string) *IndexStatement {
+	s.fields = append(s.fields, fields...)
+	return s
+}
+
+// Unique makes the index reject records whose fields hold the same values as those of another record.
+func (s *IndexStatement) Unique() *IndexStatement {
+	s.kind = "UNIQUE"
+	return s
+}
+
+// Search makes the index a full-text one, splitting text into terms with analyzer, see DefineAnalyzer.
+func (s *IndexStatement) Search(analyzer string) *IndexStatement {
+	s.kind, s.analyzer = "SEARCH", analyzer
+	return s
+}
+
+// BM25 ranks the results of a full-text index with the BM25 algorithm, tuned by k1 and b.
+func (s *IndexStatement) BM25(k1, b float64) *IndexStatement {
+	s.bm25 = &[2]float64{k1, b}
+	return s
+}
+
+// Highlights lets search::highlight mark the terms matched by a full-text index.
+func (s *IndexStatement) Highlights() *IndexStatement {
+	s.highlights = true
+	return s
+}
+
+// MTree makes the index a vector index of the given dimension, backed by an M-tree.
+func (s *IndexStatement) MTree(dimension int) *IndexStatement {
+	s.kind, s.dimension = "MTREE", dimension
+	return s
+}
+
+// HNSW makes the index a vector index of the given dimension, backed by a hierarchical navigable small world graph.
+func (s *IndexStatement) HNSW(dimension int) *IndexStatement {
+	s.kind, s.dimension = "HNSW", dimension
+	return s
+}
+
+// Distance sets the metric a vector index compares vectors with.
+func (s *IndexStatement) Distance(distance Distance) *IndexStatement {
+	s.distance = distance
+	return s
+}
+
+// EFC and M tune the size of the candidate list and the number of links per node of an HNSW index.
+func (s *IndexStatement) EFC(efc, m int) *IndexStatement {
+	s.efc, s.m = efc, m
+	return s
+}
+
+// Comment attaches a comment to the index.
+func (s *IndexStatement) Comment(comment string) *IndexStatement {
+	s.comment = &comment
+	return s
+}
+
+func (s *IndexStatement) String() string {
+	var b strings.Builder
+	b.WriteString(s.head("INDEX", ident(s.name)))
+	b.WriteString(" ON TABLE " + ident(s.table))
+	b.WriteString(" FIELDS " + fieldNames(s.fields))
+
+	switch s.kind {
+	case "UNIQUE":
+		b.WriteString(" UNIQUE")
+	case "SEARCH":
+		b.WriteString(" SEARCH ANALYZER " + ident(s.analyzer))
+		if s.bm25 != nil {
+			b.WriteString(" BM25(" + formatFloat(s.bm25[0]) + "," + formatFloat(s.bm25[1]) + ")")
+		}
+		if s.highlights {
+			b.WriteString(" HIGHLIGHTS")
+		}
+	case "MTREE", "HNSW":
+		b.WriteString(" " + s.kind + " DIMENSION " + strconv.Itoa(s.dimension))
+		if s.distance != "" {
+			b.WriteString(" DIST " + string(s.distance))
+		}
+		if s.kind == "HNSW" && s.efc > 0 {
+			b.WriteString(" EFC " + strconv.Itoa(s.efc) + " M " + strconv.Itoa(s.m))
+		}
+	}
+	b.WriteString(s.tail())
+
+	return b.String()
+}
+
+// Build implements surreal.Statement.
+func (s *IndexStatement) Build() (string, surreal.Map, error) {
+	return s.String(), nil, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,194 @@
+// Package schema builds the DEFINE statements declaring the schema of a SurrealDB database, so that it can be kept in
+// Go and rendered or applied as a whole.
+//
+//	statements := []schema.Statement{
+//		schema.DefineTable("article").Schemafull().IfNotExists(),
+//		schema.DefineField("title", "article").Type(schema.TypeString).Assert("string::len($value) > 0"),
+//		schema.DefineField("tags", "article").Type(schema.Array(schema.TypeString)).Default("[]"),
+//		schema.DefineIndex("article_title", "article").Fields("title").Unique(),
+//	}
+//	err := schema.Apply(ctx, db, statements...)
+//
+// Unlike in the builder package, values are written into the statements, since definitions outlive the query
+// defining them: expressions, like those of ASSERT and VALUE clauses, are given as SurrealQL and written as they are.
+// Names are escaped. Every statement implements surreal.Statement and can also be run on its own with DB.Execute.
+package schema
+
+import (
+	"context"
+	"github.com/terawatthour/surreal-go"
+	"strings"
+)
+
+// Statement is a DEFINE statement.
+type Statement interface {
+	surreal.Statement
+	String() string
+}
+
+// Render joins statements into a single query.
+func Render(statements ...Statement) string {
+	rendered := make([]string, len(statements))
+	for i, statement := range statements {
+		rendered[i] = statement.String() + ";"
+	}
+	return strings.Join(rendered, "\n")
+}
+
+// Apply runs statements in a single transaction, so that either all of them or none take effect.
+func Apply(ctx context.Context, db *surreal.DB, statements ...Statement) error {
+	return db.Transaction(ctx, func(tx *surreal.Tx) error {
+		for _, statement := range statements {
+			tx.Query(statement.String(), nil)
+		}
+		return nil
+	})
+}
+
+// define holds what all DEFINE statements have in common.
+type define struct {
+	ifNotExists bool
+	overwrite   bool
+	comment     *string
+}
+
+// head renders the beginning of a DEFINE statement, up to and including the name.
+func (d define) head(kind, name string) string {
+	head := "DEFINE " + kind
+	switch {
+	case d.overwrite:
+		head += " OVERWRITE"
+	case d.ifNotExists:
+		head += " IF NOT EXISTS"
+	}
+	return head + " " + name
+}
+
+// tail renders the COMMENT clause, if any.
+func (d define) tail() string {
+	if d.comment == nil {
+		return ""
+	}
+	return " COMMENT " + quote(*d.comment)
+}
+
+// quote renders s as a string literal.
+func quote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// ident escapes a name.
+func ident(name string) string {
+	return surreal.EscapeIdent(name)
+}
+
+// idents escapes names and joins them with sep.
+func idents(names []string, sep string) string {
+	escaped := make([]string, len(names))
+	for i, name := range names {
+		escaped[i] = ident(name)
+	}
+	return strings.Join(escaped, sep)
+}
+
+// fieldName escapes a field path like address.city or tags[*], part by part.
+func fieldName(path string) string {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		suffix := ""
+		for _, elements := range []string{"[*]", "[$]"} {
+			if strings.HasSuffix(part, elements) {
+				part, suffix = strings.TrimSuffix(part, elements), elements
+			}
+		}
+		if part != "*" {
+			part = ident(part)
+		}
+		parts[i] = part + suffix
+	}
+	return strings.Join(parts, ".")
+}
+
+func fieldNames(paths []string) string {
+	rendered := make([]string, len(paths))
+	for i, path := range paths {
+		rendered[i] = fieldName(path)
+	}
+	return strings.Join(rendered, ", ")
+}
+
+// Permission is who may perform an operation: PermitFull, PermitNone, or those matching Where.
+type Permission string
+
+const (
+	PermitFull Permission = "FULL"
+	PermitNone Permission = "NONE"
+)
+
+// Where permits an operation on records matching condition, a SurrealQL expression, e.g. "user = $auth.id".
+func Where(condition string) Permission {
+	return Permission("WHERE " + condition)
+}
+
+// Permissions are the permissions of a table, field or other resource, for each operation. Operations left empty are
+// not mentioned, leaving them to the server's default. Fields have no delete permission.
+type Permissions struct {
+	Select Permission
+	Create Permission
+	Update Permission
+	Delete Permission
+}
+
+// Full permits every operation.
+func Full() Permissions {
+	return Permissions{Select: PermitFull, Create: PermitFull, Update: PermitFull, Delete: PermitFull}
+}
+
+// None permits no operation.
+func None() Permissions {
+	return Permissions{Select: PermitNone, Create: PermitNone, Update: PermitNone, Delete: PermitNone}
+}
+
+// render renders the PERMISSIONS clause, grouping operations with the same permission.
+func (p *Permissions) render(operations ...string) string {
+	if p == nil {
+		return ""
+	}
+
+	byOperation := map[string]Permission{"select": p.Select, "create": p.Create, "update": p.Update, "delete": p.Delete}
+	var groups []string
+	grouped := map[Permission]int{}
+	var permissions []Permission
+	for _, operation := range operations {
+		permission := byOperation[operation]
+		if permission == "" {
+			continue
+		}
+		if i, ok := grouped[permission]; ok {
+			groups[i] += ", " + operation
+			continue
+		}
+		grouped[permission] = len(groups)
+		groups = append(groups, operation)
+		permissions = append(permissions, permission)
+	}
+
+	switch {
+	case len(groups) == 0:
+		return ""
+	case len(groups) == 1 && strings.Count(groups[0], ",")+1 == len(operations):
+		return " PERMISSIONS " + string(permissions[0])
+	}
+
+	clauses := make([]string, len(groups))
+	for i, group := range groups {
+		clauses[i] = "FOR " + group + " " + string(permissions[i])
+	}
+	return " PERMISSIONS " + strings.Join(clauses, ", ")
+}
+
+// tableOperations and fieldOperations are the operations permissions can be given for.
+var (
+	tableOperations = []string{"select", "create", "update", "delete"}
+	fieldOperations = []string{"select", "create", "update"}
+)
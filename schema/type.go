@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Type is the type of a field, as written after TYPE.
+type Type string
+
+const (
+	TypeAny      Type = "any"
+	TypeBool     Type = "bool"
+	TypeBytes    Type = "bytes"
+	TypeDatetime Type = "datetime"
+	TypeDecimal  Type = "decimal"
+	TypeDuration Type = "duration"
+	TypeFloat    Type = "float"
+	TypeInt      Type = "int"
+	TypeNumber   Type = "number"
+	TypeObject   Type = "object"
+	TypeString   Type = "string"
+	TypeUUID     Type = "uuid"
+)
+
+// Option is t or NONE, for fields that may be left out.
+func Option(t Type) Type {
+	return "option<" + t + ">"
+}
+
+// Array is an array of values of type t.
+func Array(t Type) Type {
+	return "array<" + t + ">"
+}
+
+// ArrayMax is an array of at most max values of type t.
+func ArrayMax(t Type, max int) Type {
+	return Type("array<" + string(t) + ", " + strconv.Itoa(max) + ">")
+}
+
+// Set is an array of distinct values of type t.
+func Set(t Type) Type {
+	return "set<" + t + ">"
+}
+
+// Record is a link to a record of one of tables, or of any table if none is given.
+func Record(tables ...string) Type {
+	if len(tables) == 0 {
+		return "record"
+	}
+	return Type("record<" + idents(tables, " | ") + ">")
+}
+
+// Geometry is a geometry of one of kinds, e.g. point or polygon, or of any kind if none is given.
+func Geometry(kinds ...string) Type {
+	if len(kinds) == 0 {
+		return "geometry"
+	}
+	return Type("geometry<" + strings.Join(kinds, " | ") + ">")
+}
+
+// Either is a value of any of types.
+func Either(types ...Type) Type {
+	rendered := make([]string, len(types))
+	for i, t := range types {
+		rendered[i] = string(t)
+	}
+	return Type(strings.Join(rendered, " | "))
+}
@@ -0,0 +1,132 @@
+package surreal
+
+import "sync"
+
+// session remembers the calls that shape the server-side state of a connection, so that it can be rebuilt after the
+// connection is re-established.
+type session struct {
+	lock  sync.Mutex
+	codec codec
+
+	use   []any
+	token string
+	vars  map[string]any
+
+	// lives maps live query ids handed out to callers onto the live query they were started with
+	lives map[string]*liveQuery
+}
+
+type liveQuery struct {
+	params   []any
+	serverID string
+}
+
+type sessionSnapshot struct {
+	use   []any
+	token string
+	vars  map[string]any
+	lives map[string][]any
+}
+
+func newSession(c codec) *session {
+	return &session{
+		codec: c,
+		vars:  make(map[string]any),
+		lives: make(map[string]*liveQuery),
+	}
+}
+
+// record updates the session after a call has succeeded.
+func (s *session) record(method string, params []any, result []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch method {
+	case "use":
+		s.use = params
+	case "signin", "signup":
+		s.token = decodeToken(s.codec, result)
+	case "authenticate":
+		if len(params) == 1 {
+			s.token, _ = params[0].(string)
+		}
+	case "invalidate":
+		s.token = ""
+	case "let":
+		if len(params) == 2 {
+			if identifier, ok := params[0].(string); ok {
+				s.vars[identifier] = params[1]
+			}
+		}
+	case "unset":
+		if len(params) == 1 {
+			if identifier, ok := params[0].(string); ok {
+				delete(s.vars, identifier)
+			}
+		}
+	case "live":
+		if id, err := decodeString(s.codec, result); err == nil {
+			s.lives[id] = &liveQuery{params: params, serverID: id}
+		}
+	case "kill":
+		if len(params) == 1 {
+			if id, ok := params[0].(string); ok {
+				delete(s.lives, id)
+			}
+		}
+	}
+}
+
+// serverLiveID translates a live query id handed out to a caller into the id the server currently knows it by.
+func (s *session) serverLiveID(id string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if live, ok := s.lives[id]; ok {
+		return live.serverID
+	}
+	return id
+}
+
+func (s *session) remapLive(id, serverID string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if live, ok := s.lives[id]; ok {
+		live.serverID = serverID
+	}
+}
+
+func (s *session) snapshot() sessionSnapshot {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	snapshot := sessionSnapshot{
+		use:   s.use,
+		token: s.token,
+		vars:  make(map[string]any, len(s.vars)),
+		lives: make(map[string][]any, len(s.lives)),
+	}
+	for identifier, value := range s.vars {
+		snapshot.vars[identifier] = value
+	}
+	for id, live := range s.lives {
+		snapshot.lives[id] = live.params
+	}
+
+	return snapshot
+}
+
+// decodeToken extracts the session token from the result of a signin or signup call.
+func decodeToken(c codec, result []byte) string {
+	var token string
+	if err := c.unmarshal(result, &token); err == nil {
+		return token
+	}
+
+	var tokens struct {
+		Token string `json:"token"`
+	}
+	_ = c.unmarshal(result, &tokens)
+	return tokens.Token
+}
@@ -0,0 +1,315 @@
+package surreal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/terawatthour/surreal-go/rpc"
+	"sync"
+	"sync/atomic"
+)
+
+const DefaultSubscriptionBufferSize = 64
+
+// subscriptionBacklogSize is the number of notifications an OverflowBlock subscription holds back while its buffer is
+// full, before it gives up with ErrSubscriptionOverflow.
+const subscriptionBacklogSize = 256
+
+var (
+	// ErrSubscriptionClosed is reported by Subscription.Err once the subscription has been closed with Close.
+	ErrSubscriptionClosed = errors.New("subscription closed")
+
+	// ErrSubscriptionOverflow is reported by Subscription.Err once a subscription with the OverflowClose policy has
+	// been closed because its buffer was full.
+	ErrSubscriptionOverflow = errors.New("subscription buffer overflowed")
+
+	// ErrLiveQueryKilled is reported by Subscription.Err once the server has closed the live query on its own, e.g.
+	// because the table was removed or the session's permissions changed.
+	ErrLiveQueryKilled = errors.New("live query killed by the server")
+)
+
+// OverflowPolicy decides what happens to a notification arriving while the buffer of a Subscription is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until the notification fits into the buffer, holding back the notifications after it. Other
+	// subscriptions and responses on the connection are not held back. Once 256 notifications are waiting, the
+	// subscription is closed, and its live query killed, with ErrSubscriptionOverflow.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropNewest discards the notification that does not fit.
+	OverflowDropNewest
+
+	// OverflowDropOldest discards the oldest buffered notification to make room for the new one.
+	OverflowDropOldest
+
+	// OverflowClose closes the subscription, and kills its live query, with ErrSubscriptionOverflow.
+	OverflowClose
+)
+
+type SubscriptionOptions struct {
+	// Diff makes notifications carry JSON Patch diffs instead of whole records.
+	Diff bool
+
+	// BufferSize is the capacity of the notifications channel. Defaults to DefaultSubscriptionBufferSize.
+	BufferSize int
+
+	// Overflow is the policy applied once the buffer is full. Defaults to OverflowBlock.
+	Overflow OverflowPolicy
+}
+
+func (o *SubscriptionOptions) bufferSize() int {
+	if o.BufferSize <= 0 {
+		return DefaultSubscriptionBufferSize
+	}
+	return o.BufferSize
+}
+
+// Subscription is a live query whose notifications are delivered, in the order the server sent them, over a channel.
+type Subscription struct {
+	*subscriber[rpc.LiveNotification]
+}
+
+// Subscribe starts a live query on a table and returns a Subscription delivering its notifications.
+func (db *DB) Subscribe(table string, options *SubscriptionOptions) (*Subscription, error) {
+	return db.SubscribeContext(context.Background(), table, options)
+}
+
+// SubscribeContext is like Subscribe, but the request is abandoned once ctx is done. Cancelling ctx after the live
+// query has been started does not close the subscription, use Close for that.
+func (db *DB) SubscribeContext(ctx context.Context, table string, options *SubscriptionOptions) (*Subscription, error) {
+	s, err := subscribe(ctx, db, table, options, func(notification rpc.LiveNotification) (rpc.LiveNotification, error) {
+		return notification, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Subscription{s}, nil
+}
+
+// Notifications returns the channel notifications are delivered over. It is closed once the subscription ends, after
+// which Err reports why.
+func (s *Subscription) Notifications() <-chan rpc.LiveNotification {
+	return s.ch
+}
+
+// subscriber holds the machinery shared by Subscription and LiveSubscription, which only differ in what they deliver.
+type subscriber[E any] struct {
+	db      *DB
+	id      string
+	options SubscriptionOptions
+	decode  func(notification rpc.LiveNotification) (E, error)
+
+	ch      chan E
+	dropped atomic.Uint64
+
+	// backlog holds the notifications of an OverflowBlock subscription until they fit into ch
+	backlog chan E
+
+	// closing is closed as soon as the subscription starts shutting down, releasing a blocked delivery
+	closing     chan struct{}
+	closingOnce sync.Once
+
+	lock   sync.Mutex
+	closed bool
+	err    error
+}
+
+func subscribe[E any](ctx context.Context, db *DB, table string, options *SubscriptionOptions, decode func(rpc.LiveNotification) (E, error)) (*subscriber[E], error) {
+	if options == nil {
+		options = &SubscriptionOptions{}
+	}
+
+	raw, err := db.conn.Send(ctx, "live", []any{table, options.Diff})
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := decodeString(db.codec, raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start live query: %s", err)
+	}
+
+	s := &subscriber[E]{
+		db:      db,
+		id:      id,
+		options: *options,
+		decode:  decode,
+		ch:      make(chan E, options.bufferSize()),
+		closing: make(chan struct{}),
+	}
+
+	if s.options.Overflow == OverflowBlock {
+		s.backlog = make(chan E, subscriptionBacklogSize)
+		go s.drain()
+	}
+
+	db.conn.RegisterLiveCallback(id, s.deliver)
+
+	if c, ok := db.conn.(interface{ closed() <-chan struct{} }); ok {
+		go func() {
+			select {
+			case <-s.closing:
+			case <-c.closed():
+				s.terminate(ErrClosed)
+			}
+		}()
+	}
+
+	return s, nil
+}
+
+// ID returns the id of the underlying live query.
+func (s *subscriber[E]) ID() string {
+	return s.id
+}
+
+// Err returns nil while the subscription is running, and the reason it ended afterwards.
+func (s *subscriber[E]) Err() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.err
+}
+
+// Dropped returns the number of notifications discarded by the OverflowDropNewest and OverflowDropOldest policies.
+func (s *subscriber[E]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close kills the live query and closes the channel. Notifications still buffered remain readable.
+func (s *subscriber[E]) Close() error {
+	return s.CloseContext(context.Background())
+}
+
+// CloseContext is like Close, but the kill request is abandoned once ctx is done. The subscription is closed either way.
+func (s *subscriber[E]) CloseContext(ctx context.Context) error {
+	if !s.terminate(ErrSubscriptionClosed) {
+		return nil
+	}
+	return s.db.KillContext(ctx, s.id)
+}
+
+// terminate ends the subscription with reason, reporting false if it had already ended.
+func (s *subscriber[E]) terminate(reason error) bool {
+	s.closingOnce.Do(func() {
+		close(s.closing)
+	})
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return false
+	}
+	s.closed = true
+	s.err = reason
+	close(s.ch)
+
+	return true
+}
+
+// deliver is called on the goroutine dispatching the connection's notifications, one notification at a time. It never
+// blocks, so that a subscription that is not drained holds back no other one.
+func (s *subscriber[E]) deliver(notification rpc.LiveNotification) {
+	if notification.Action == rpc.Close {
+		if s.terminate(ErrLiveQueryKilled) {
+			go s.kill()
+		}
+		return
+	}
+
+	item, err := s.decode(notification)
+	if err != nil {
+		if s.terminate(fmt.Errorf("failed to decode live notification: %w", err)) {
+			go s.kill()
+		}
+		return
+	}
+
+	// the backlog is drained apart, which takes the lock while waiting for the notification to fit
+	if s.options.Overflow == OverflowBlock {
+		select {
+		case s.backlog <- item:
+		default:
+			if s.terminate(ErrSubscriptionOverflow) {
+				go s.kill()
+			}
+		}
+		return
+	}
+
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return
+	}
+
+	switch s.options.Overflow {
+	case OverflowDropNewest:
+		select {
+		case s.ch <- item:
+		default:
+			s.drop()
+		}
+	case OverflowDropOldest:
+		for delivered := false; !delivered; {
+			select {
+			case s.ch <- item:
+				delivered = true
+			default:
+				select {
+				case <-s.ch:
+					s.drop()
+				default:
+				}
+			}
+		}
+	case OverflowClose:
+		select {
+		case s.ch <- item:
+		default:
+			s.lock.Unlock()
+			if s.terminate(ErrSubscriptionOverflow) {
+				go s.kill()
+			}
+			return
+		}
+	}
+	s.lock.Unlock()
+}
+
+// drain moves the backlog of an OverflowBlock subscription into its channel, waiting for each notification to fit,
+// until the subscription ends.
+func (s *subscriber[E]) drain() {
+	for {
+		select {
+		case <-s.closing:
+			return
+		case item := <-s.backlog:
+			s.lock.Lock()
+			if !s.closed {
+				select {
+				case s.ch <- item:
+				case <-s.closing:
+				}
+			}
+			s.lock.Unlock()
+		}
+	}
+}
+
+// drop counts a notification discarded because the buffer was full.
+func (s *subscriber[E]) drop() {
+	s.dropped.Add(1)
+	s.db.options.logger().Warn("subscription dropped live notification", "live_id", s.id)
+	if callback := s.db.options.OnNotificationDroppedCallback; callback != nil {
+		callback(s.id)
+	}
+}
+
+// kill releases the live query of a subscription that ended on its own. It runs on a goroutine of its own, so that
+// notifications of other live queries are not held back while waiting for the response.
+func (s *subscriber[E]) kill() {
+	_ = s.db.Kill(s.id)
+}
@@ -2,36 +2,77 @@ package surreal
 
 import (
 	"fmt"
+	"github.com/terawatthour/surreal-go/rpc"
+	"log/slog"
 	"net/url"
+	"time"
 )
 
 type Options struct {
-	Verbose          bool
+	// Verbose reports events to the default logger, unless Logger is set.
+	//
+	// Deprecated: set Logger instead.
+	Verbose bool
+
+	// Logger receives structured events: connecting and disconnecting, failed pings, reads, writes and decodes,
+	// responses nobody waits for, dropped live notifications and slow calls. Events are not reported if unset.
+	Logger *slog.Logger
+
+	// SlowCallThreshold is the duration after which a call is reported as slow, at the warning level. Zero disables
+	// the reports.
+	SlowCallThreshold time.Duration
+
+	// Encoding is the format messages are exchanged in. Defaults to EncodingJSON.
+	Encoding Encoding
+
 	WebSocketOptions WebSocketOptions
+	HTTPOptions      HTTPOptions
+	PoolOptions      PoolOptions
+
+	// Interceptors are invoked around every call, the first one outermost. Batches are sent call by call, rather than
+	// pipelined, when interceptors are set.
+	Interceptors []Interceptor
+
+	// OnNotificationCallback is called with every live notification received, before it is dispatched. It runs on the
+	// goroutine reading the connection, so it must not block.
+	OnNotificationCallback func(notification rpc.LiveNotification)
+
+	// OnNotificationDroppedCallback is called with the live query id of every notification a subscription discards
+	// because its buffer is full, or the connection discards because live callbacks have fallen behind.
+	OnNotificationDroppedCallback func(id string)
 }
 
 func Connect(connectionUrl string, options *Options) (*DB, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	conn, err := establishConnection(connectionUrl, options)
+	if err != nil {
+		return nil, err
+	}
+
+	go conn.Run()
+
+	return &DB{
+		conn:    intercept(conn, options.Interceptors),
+		options: options,
+		codec:   options.Encoding.codec(),
+	}, nil
+}
+
+func establishConnection(connectionUrl string, options *Options) (Connection, error) {
 	parsedUrl, err := url.Parse(connectionUrl)
 	if err != nil {
 		return nil, fmt.Errorf("invalid connection url: %s", err)
 	}
 
-	var conn Connection
-
 	switch parsedUrl.Scheme {
 	case "ws", "wss":
-		conn, err = establishWebsocketConnection(connectionUrl, options)
-		if err != nil {
-			return nil, err
-		}
+		return establishWebsocketConnection(connectionUrl, options)
+	case "http", "https":
+		return establishHTTPConnection(parsedUrl, options)
 	default:
 		return nil, fmt.Errorf("unsupported connection url scheme: %s", parsedUrl.Scheme)
 	}
-
-	go conn.Run()
-
-	return &DB{
-		conn:    conn,
-		options: options,
-	}, nil
 }
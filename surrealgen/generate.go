@@ -0,0 +1,397 @@
+package surrealgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultPackage is the package generated code belongs to unless Options.Package is set.
+const DefaultPackage = "schema"
+
+// Options configure Generate.
+type Options struct {
+	// Package is the name of the package the code is generated for, DefaultPackage if empty.
+	Package string
+}
+
+func (o *Options) pkg() string {
+	if o == nil || o.Package == "" {
+		return DefaultPackage
+	}
+	return o.Package
+}
+
+// Generate renders Go source declaring, for each table of the schema, a surreal.Table constant named after it and a
+// struct with a field for each of its fields. Records are identified by a surreal.RecordID field, objects with fields
+// of their own become structs too, and optional fields become pointers, left out when nil. Fields are tagged as
+// described in the surreal package, so that readonly fields are never sent.
+func Generate(schema *Schema, options *Options) ([]byte, error) {
+	g := &generator{names: map[string]bool{}}
+	// constants are named first, so that structs clashing with them are the ones renamed
+	constants := make([]string, len(schema.Tables))
+	for i, table := range schema.Tables {
+		constants[i] = g.unique("Table" + exported(table.Name))
+	}
+	for _, table := range schema.Tables {
+		g.table(table)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by surreal-gen. DO NOT EDIT.\n\n")
+	b.WriteString("package " + options.pkg() + "\n\n")
+	b.WriteString("import \"github.com/terawatthour/surreal-go\"\n\n")
+
+	if len(schema.Tables) > 0 {
+		b.WriteString("const (\n")
+		for i, table := range schema.Tables {
+			name := constants[i]
+			fmt.Fprintf(&b, "// %s is the %s table.\n%s surreal.Table = %s\n", name, table.Name, name, strconv.Quote(table.Name))
+		}
+		b.WriteString(")\n\n")
+	}
+	for _, declaration := range g.declarations {
+		b.WriteString(declaration)
+	}
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return source, nil
+}
+
+// node is a field along with the fields defined within it, e.g. address holding address.city.
+type node struct {
+	field    *Field
+	children map[string]*node
+	order    []string
+}
+
+func (n *node) insert(path []string, field *Field) {
+	if len(path) == 0 {
+		n.field = field
+		return
+	}
+	if n.children == nil {
+		n.children = map[string]*node{}
+	}
+	child, ok := n.children[path[0]]
+	if !ok {
+		child = &node{}
+		n.children[path[0]] = child
+		n.order = append(n.order, path[0])
+	}
+	child.insert(path[1:], field)
+}
+
+func (n *node) child(name string) *node {
+	if n == nil {
+		return nil
+	}
+	return n.children[name]
+}
+
+// fields returns the names of the fields of an object, leaving out * which stands for its elements.
+func (n *node) fields() []string {
+	if n == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range n.order {
+		if name != "*" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (n *node) typ() *Type {
+	if n == nil || n.field == nil {
+		return nil
+	}
+	return n.field.Type
+}
+
+type generator struct {
+	declarations []string
+	names        map[string]bool
+}
+
+// unique returns name, or name followed by a number if it is taken.
+func (g *generator) unique(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+func (g *generator) table(table Table) {
+	root := &node{}
+	for i := range table.Fields {
+		root.insert(table.Fields[i].Path, &table.Fields[i])
+	}
+
+	fields := []string{"id"}
+	if table.Relation {
+		for _, name := range []string{"in", "out"} {
+			if root.child(name) == nil {
+				fields = append(fields, name)
+			}
+		}
+	}
+
+	name := exported(table.Name)
+	g.object(root, name, fmt.Sprintf("%s is a record of the %s table.", name, table.Name), fields)
+}
+
+// object declares a struct for the fields of n and returns its name. extra are fields every record has, which are
+// not defined in the schema.
+func (g *generator) object(n *node, name, doc string, extra []string) string {
+	name = g.unique(name)
+	// the struct goes before the structs declared for its fields
+	index := len(g.declarations)
+	g.declarations = append(g.declarations, "")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s\ntype %s struct {\n", doc, name)
+
+	taken := map[string]bool{}
+	field := func(key string) string {
+		goName := exported(key)
+		for i := 2; taken[goName]; i++ {
+			goName = exported(key) + strconv.Itoa(i)
+		}
+		taken[goName] = true
+		return goName
+	}
+
+	for _, key := range extra {
+		fmt.Fprintf(&b, "%s surreal.RecordID `surreal:\"%s,readonly\"`\n", field(key), key)
+	}
+	for _, key := range n.fields() {
+		if key == "id" {
+			continue
+		}
+
+		child := n.children[key]
+		goName := field(key)
+		goType := g.goType(child, child.typ(), name+goName)
+
+		options := ""
+		if optional(child.typ()) {
+			options += ",omitempty"
+		}
+		if child.field != nil && child.field.Readonly {
+			options += ",readonly"
+		}
+		if child.field != nil && child.field.Comment != "" {
+			for _, line := range strings.Split(child.field.Comment, "\n") {
+				b.WriteString("// " + line + "\n")
+			}
+		}
+		fmt.Fprintf(&b, "%s %s `surreal:%s`\n", goName, goType, strconv.Quote(key+options))
+	}
+	b.WriteString("}\n\n")
+
+	g.declarations[index] = b.String()
+	return name
+}
+
+// goType returns the Go type for values of type t, n being the field holding them, if any. name is what a struct
+// declared for the value is called.
+func (g *generator) goType(n *node, t *Type, name string) string {
+	if t == nil {
+		switch {
+		case len(n.fields()) > 0:
+			return g.object(n, name, name+" is an object.", nil)
+		case n.child("*") != nil:
+			return "[]" + g.goType(n.child("*"), n.child("*").typ(), name+"Item")
+		default:
+			return "any"
+		}
+	}
+
+	switch t.Kind {
+	case "option":
+		inner := g.goType(n, t.Elem, name)
+		if nillable(inner) {
+			return inner
+		}
+		return "*" + inner
+	case "array", "set":
+		elem := t.Elem
+		if elem == nil {
+			elem = n.child("*").typ()
+		}
+		return "[]" + g.goType(n.child("*"), elem, name+"Item")
+	case "object":
+		if len(n.fields()) > 0 {
+			return g.object(n, name, name+" is an object.", nil)
+		}
+		return "map[string]any"
+	case "either":
+		var variants []*Type
+		for _, variant := range t.Variants {
+			if variant.Kind != "none" && variant.Kind != "null" {
+				variants = append(variants, variant)
+			}
+		}
+		if len(variants) < len(t.Variants) {
+			return g.goType(n, &Type{Kind: "option", Elem: &Type{Kind: "either", Variants: variants}}, name)
+		}
+		if len(variants) == 0 {
+			return "any"
+		}
+		if len(variants) == 1 {
+			return g.goType(n, variants[0], name)
+		}
+		// a union of values of the same Go type, like 'draft' | 'published', is of that type
+		first := scalar(variants[0])
+		for _, variant := range variants[1:] {
+			if scalar(variant) != first {
+				return "any"
+			}
+		}
+		return first
+	default:
+		return scalar(t)
+	}
+}
+
+// scalar returns the Go type for values of a type holding no fields, any if there is none more specific.
+func scalar(t *Type) string {
+	switch t.Kind {
+	case "string":
+		return "string"
+	case "int":
+		return "int64"
+	case "float", "number":
+		return "float64"
+	case "decimal":
+		return "surreal.Decimal"
+	case "bool":
+		return "bool"
+	case "datetime":
+		return "surreal.Datetime"
+	case "duration":
+		return "surreal.Duration"
+	case "uuid":
+		return "surreal.UUID"
+	case "bytes":
+		return "[]byte"
+	case "record":
+		return "surreal.RecordID"
+	case "object":
+		return "map[string]any"
+	case "geometry":
+		if len(t.Tables) == 1 {
+			switch t.Tables[0] {
+			case "point":
+				return "surreal.Point"
+			case "line":
+				return "surreal.LineString"
+			case "polygon":
+				return "surreal.Polygon"
+			case "multipoint":
+				return "surreal.MultiPoint"
+			case "multiline":
+				return "surreal.MultiLineString"
+			case "multipolygon":
+				return "surreal.MultiPolygon"
+			case "collection":
+				return "surreal.GeometryCollection"
+			}
+		}
+		return "any"
+	case "literal":
+		switch literal := t.Literal; {
+		case literal[0] == '\'' || literal[0] == '"':
+			return "string"
+		case literal == "true" || literal == "false":
+			return "bool"
+		case strings.ContainsAny(literal, "0123456789") && strings.Trim(literal, "+-0123456789") == "":
+			return "int64"
+		case strings.Trim(literal, "+-.e0123456789") == "":
+			return "float64"
+		}
+		return "any"
+	default:
+		return "any"
+	}
+}
+
+// optional tells whether a field of type t may be left out, i.e. be NONE.
+func optional(t *Type) bool {
+	if t == nil {
+		return false
+	}
+	if t.Kind == "option" {
+		return true
+	}
+	for _, variant := range t.Variants {
+		if variant.Kind == "none" {
+			return true
+		}
+	}
+	return false
+}
+
+func nillable(goType string) bool {
+	return goType == "any" || strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || strings.HasPrefix(goType, "*")
+}
+
+// initialisms are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "https": true, "id": true, "ip": true, "json": true, "sql": true,
+	"ttl": true, "uid": true, "uri": true, "url": true, "uuid": true,
+}
+
+// exported turns a SurrealQL name, e.g. created_at or createdAt, into an exported Go name, e.g. CreatedAt.
+func exported(name string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+
+	previous := ' '
+	for _, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && (unicode.IsLower(previous) || unicode.IsDigit(previous)):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+		previous = r
+	}
+	flush()
+
+	var b strings.Builder
+	for _, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+
+	result := b.String()
+	if result == "" || !unicode.IsLetter([]rune(result)[0]) {
+		result = "X" + result
+	}
+	return result
+}
@@ -0,0 +1,428 @@
+package surrealgen
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Type is the type of a field, as given with TYPE.
+type Type struct {
+	// Kind is the name of the type in lower case, e.g. string, option, array or record. Unions of several types are of
+	// kind either, and literal values, like 'draft' in TYPE 'draft' | 'published', of kind literal.
+	Kind string
+	// Elem is the type wrapped by option<>, or of the elements of array<> and set<>, nil if not given.
+	Elem *Type
+	// Variants are the types of a union.
+	Variants []*Type
+	// Tables are the tables a record<> links to, or the kinds of a geometry<>, e.g. point.
+	Tables []string
+	// Literal is the text of a literal value.
+	Literal string
+}
+
+// String formats the type back into SurrealQL.
+func (t *Type) String() string {
+	switch t.Kind {
+	case "option", "array", "set":
+		if t.Elem == nil {
+			return t.Kind
+		}
+		return t.Kind + "<" + t.Elem.String() + ">"
+	case "record", "geometry":
+		if len(t.Tables) == 0 {
+			return t.Kind
+		}
+		return t.Kind + "<" + strings.Join(t.Tables, " | ") + ">"
+	case "either":
+		variants := make([]string, len(t.Variants))
+		for i, variant := range t.Variants {
+			variants[i] = variant.String()
+		}
+		return strings.Join(variants, " | ")
+	case "literal":
+		return t.Literal
+	default:
+		return t.Kind
+	}
+}
+
+// clauses are the keywords starting the clauses of a DEFINE FIELD statement.
+var clauses = map[string]bool{
+	"TYPE": true, "FLEXIBLE": true, "DEFAULT": true, "READONLY": true, "VALUE": true, "ASSERT": true,
+	"PERMISSIONS": true, "COMMENT": true, "REFERENCE": true,
+}
+
+// ParseField parses a DEFINE FIELD statement, as returned by INFO FOR TABLE.
+func ParseField(definition string) (Field, error) {
+	words := splitWords(definition)
+	if len(words) < 2 || !strings.EqualFold(words[0], "DEFINE") || !strings.EqualFold(words[1], "FIELD") {
+		return Field{}, fmt.Errorf("invalid field definition: %s", definition)
+	}
+	words = words[2:]
+
+	// IF NOT EXISTS and OVERWRITE only matter when the statement is run
+	if len(words) >= 3 && strings.EqualFold(words[0], "IF") {
+		words = words[3:]
+	} else if len(words) >= 1 && strings.EqualFold(words[0], "OVERWRITE") {
+		words = words[1:]
+	}
+	if len(words) < 3 || !strings.EqualFold(words[1], "ON") {
+		return Field{}, fmt.Errorf("invalid field definition: %s", definition)
+	}
+
+	var field Field
+	path, err := parsePath(words[0])
+	if err != nil {
+		return Field{}, err
+	}
+	field.Path = path
+
+	words = words[2:]
+	if strings.EqualFold(words[0], "TABLE") {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return Field{}, fmt.Errorf("invalid field definition: %s", definition)
+	}
+	words = words[1:]
+
+	for len(words) > 0 {
+		switch strings.ToUpper(words[0]) {
+		case "FLEXIBLE":
+			field.Flexible = true
+			words = words[1:]
+		case "READONLY":
+			field.Readonly = true
+			words = words[1:]
+		case "TYPE":
+			// a type like option<string | int> is split into several words; it ends at the first clause keyword
+			// found outside of <>
+			end, depth := 1, 0
+			for ; end < len(words) && (depth > 0 || !clauses[strings.ToUpper(words[end])]); end++ {
+				depth += strings.Count(words[end], "<") - strings.Count(words[end], ">")
+			}
+			if field.Type, err = ParseType(strings.Join(words[1:end], " ")); err != nil {
+				return Field{}, err
+			}
+			words = words[end:]
+		case "COMMENT":
+			if len(words) < 2 {
+				return Field{}, fmt.Errorf("invalid field definition: %s", definition)
+			}
+			field.Comment = unquote(words[1])
+			words = words[2:]
+		default:
+			// the expressions of other clauses are skipped up to the next clause
+			end := 1
+			for end < len(words) && !clauses[strings.ToUpper(words[end])] {
+				end++
+			}
+			words = words[end:]
+		}
+	}
+
+	return field, nil
+}
+
+// parsePath splits the name of a field into its parts.
+func parsePath(name string) ([]string, error) {
+	var path []string
+	for name != "" {
+		switch {
+		case strings.HasPrefix(name, "[*]"), strings.HasPrefix(name, "[$]"):
+			path = append(path, "*")
+			name = name[3:]
+			continue
+		case name[0] == '.':
+			name = name[1:]
+			continue
+		}
+
+		part, rest, err := readIdent(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field name %s: %s", name, err)
+		}
+		path = append(path, part)
+		name = rest
+	}
+
+	if len(path) == 0 {
+		return nil, fmt.Errorf("invalid field name %q", name)
+	}
+	return path, nil
+}
+
+// readIdent reads an identifier, plain or escaped, from the beginning of s. * stands for any field.
+func readIdent(s string) (ident, rest string, err error) {
+	switch {
+	case strings.HasPrefix(s, "⟨"):
+		return readEscaped(s[len("⟨"):], "⟩")
+	case s[0] == '`':
+		return readEscaped(s[1:], "`")
+	case s[0] == '*':
+		return "*", s[1:], nil
+	}
+
+	end := 0
+	for end < len(s) && isIdentByte(s[end]) {
+		end++
+	}
+	if end == 0 {
+		return "", s, fmt.Errorf("expected identifier at %q", s)
+	}
+	return s[:end], s[end:], nil
+}
+
+func readEscaped(s, closing string) (ident, rest string, err error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			_, size := utf8.DecodeRuneInString(s[i+1:])
+			b.WriteString(s[i+1 : i+1+size])
+			i += 1 + size
+		case strings.HasPrefix(s[i:], closing):
+			return b.String(), s[i+len(closing):], nil
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return "", "", fmt.Errorf("unterminated identifier")
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+// unquote returns the content of a string literal.
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != s[len(s)-1] || (s[0] != '\'' && s[0] != '"') {
+		return s
+	}
+	var b strings.Builder
+	for i := 1; i < len(s)-1; i++ {
+		if s[i] == '\\' && i+1 < len(s)-1 {
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+				continue
+			case 't':
+				b.WriteByte('\t')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// splitWords splits a statement at whitespace, keeping strings, escaped identifiers and anything in (), [] or {}
+// whole.
+func splitWords(s string) []string {
+	var words []string
+	start, depth := -1, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if start < 0 {
+			if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+				continue
+			}
+			start = i
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			for i++; i < len(s) && s[i] != c; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		case strings.HasPrefix(s[i:], "⟨"):
+			for ; i < len(s) && !strings.HasPrefix(s[i:], "⟩"); i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case depth <= 0 && (c == ' ' || c == '\t' || c == '\r' || c == '\n'):
+			words = append(words, s[start:i])
+			start, depth = -1, 0
+		}
+	}
+	if start >= 0 && start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// ParseType parses a SurrealQL type, e.g. option<array<record<user>>>.
+func ParseType(s string) (*Type, error) {
+	p := &typeParser{s: s}
+	t, err := p.union()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return nil, fmt.Errorf("invalid type %q: unexpected %q", s, p.s[p.pos:])
+	}
+	return t, nil
+}
+
+type typeParser struct {
+	s   string
+	pos int
+}
+
+func (p *typeParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *typeParser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *typeParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid type %q at %d: %s", p.s, p.pos, fmt.Sprintf(format, args...))
+}
+
+// union parses one or more types separated by |.
+func (p *typeParser) union() (*Type, error) {
+	t, err := p.single()
+	if err != nil {
+		return nil, err
+	}
+
+	variants := []*Type{t}
+	for p.consume('|') {
+		if t, err = p.single(); err != nil {
+			return nil, err
+		}
+		variants = append(variants, t)
+	}
+
+	if len(variants) == 1 {
+		return variants[0], nil
+	}
+	return &Type{Kind: "either", Variants: variants}, nil
+}
+
+func (p *typeParser) single() (*Type, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("expected a type")
+	}
+	if c := p.s[p.pos]; !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') || p.isKeywordLiteral() {
+		return p.literal()
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && isIdentByte(p.s[p.pos]) {
+		p.pos++
+	}
+	t := &Type{Kind: strings.ToLower(p.s[start:p.pos])}
+	if !p.consume('<') {
+		return t, nil
+	}
+
+	switch t.Kind {
+	case "option", "array", "set":
+		elem, err := p.union()
+		if err != nil {
+			return nil, err
+		}
+		t.Elem = elem
+		// the maximum length of array<string, 10> is of no use here
+		if p.consume(',') {
+			p.skipSpace()
+			for p.pos < len(p.s) && '0' <= p.s[p.pos] && p.s[p.pos] <= '9' {
+				p.pos++
+			}
+		}
+	case "record", "geometry":
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.s) {
+				return nil, p.errorf("expected a table")
+			}
+			name, rest, err := readIdent(p.s[p.pos:])
+			if err != nil {
+				return nil, p.errorf("%s", err)
+			}
+			t.Tables = append(t.Tables, name)
+			p.pos = len(p.s) - len(rest)
+			if !p.consume('|') {
+				break
+			}
+		}
+	default:
+		// the parameters of other types are skipped
+		for depth := 1; depth > 0; p.pos++ {
+			if p.pos >= len(p.s) {
+				return nil, p.errorf("expected >")
+			}
+			switch p.s[p.pos] {
+			case '<':
+				depth++
+			case '>':
+				depth--
+			}
+		}
+		return t, nil
+	}
+
+	if !p.consume('>') {
+		return nil, p.errorf("expected >")
+	}
+	return t, nil
+}
+
+// isKeywordLiteral tells whether a literal value starting with a letter, true or false, comes next.
+func (p *typeParser) isKeywordLiteral() bool {
+	for _, keyword := range []string{"true", "false"} {
+		if end := p.pos + len(keyword); strings.HasPrefix(p.s[p.pos:], keyword) && (end == len(p.s) || !isIdentByte(p.s[end])) {
+			return true
+		}
+	}
+	return false
+}
+
+// literal reads a literal value, e.g. 'draft', 42 or { kind: 'a' }, up to the next | or > outside of it.
+func (p *typeParser) literal() (*Type, error) {
+	start, depth := p.pos, 0
+scan:
+	for ; p.pos < len(p.s); p.pos++ {
+		switch c := p.s[p.pos]; {
+		case c == '\'' || c == '"':
+			for p.pos++; p.pos < len(p.s) && p.s[p.pos] != c; p.pos++ {
+				if p.s[p.pos] == '\\' {
+					p.pos++
+				}
+			}
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case depth == 0 && (c == '|' || c == '>' || c == ','):
+			break scan
+		}
+	}
+
+	text := strings.TrimSpace(p.s[start:min(p.pos, len(p.s))])
+	if text == "" {
+		return nil, p.errorf("expected a type")
+	}
+	return &Type{Kind: "literal", Literal: text}, nil
+}
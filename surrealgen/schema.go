@@ -0,0 +1,132 @@
+// Package surrealgen generates Go types mirroring the schema of a SurrealDB database: a struct for each table, with a
+// field for each DEFINE FIELD, and a surreal.Table constant naming it.
+//
+//	schema, err := surrealgen.Inspect(db)
+//	if err != nil {
+//		return err
+//	}
+//	source, err := surrealgen.Generate(schema, &surrealgen.Options{Package: "models"})
+//
+// The cmd/surreal-gen command does the same from the command line.
+package surrealgen
+
+import (
+	"context"
+	"fmt"
+	"github.com/terawatthour/surreal-go"
+	"sort"
+	"strings"
+)
+
+// Schema is the set of tables defined in a database.
+type Schema struct {
+	Tables []Table
+}
+
+// Table is a table along with the fields defined on it.
+type Table struct {
+	Name string
+	// Relation is set for tables defined with TYPE RELATION, whose records link an in record to an out one.
+	Relation bool
+	Fields   []Field
+}
+
+// Field is a field defined with DEFINE FIELD.
+type Field struct {
+	// Path is the name of the field split into its parts, e.g. address.city is [address city], and tags[*], the
+	// elements of the tags array, is [tags *].
+	Path []string
+	// Type is the type given with TYPE, nil if the field accepts any value.
+	Type     *Type
+	Flexible bool
+	Readonly bool
+	Comment  string
+}
+
+// Name returns the name of the field as written in SurrealQL, e.g. address.city or tags[*].
+func (f Field) Name() string {
+	var b strings.Builder
+	for i, part := range f.Path {
+		switch {
+		case part == "*":
+			b.WriteString("[*]")
+		case i > 0:
+			b.WriteString("." + surreal.EscapeIdent(part))
+		default:
+			b.WriteString(surreal.EscapeIdent(part))
+		}
+	}
+	return b.String()
+}
+
+// Inspect reads the schema of the database in use with INFO FOR DB and INFO FOR TABLE. Only the given tables are
+// inspected, or all of them if none are given.
+func Inspect(db *surreal.DB, tables ...string) (*Schema, error) {
+	return InspectContext(context.Background(), db, tables...)
+}
+
+// InspectContext is like Inspect, but the queries are abandoned once ctx is done.
+func InspectContext(ctx context.Context, db *surreal.DB, tables ...string) (*Schema, error) {
+	var info struct {
+		Tables map[string]string `json:"tables"`
+	}
+	if err := db.QueryContext(ctx, "INFO FOR DB", nil, &info); err != nil {
+		return nil, fmt.Errorf("failed to read database info: %w", err)
+	}
+
+	names := tables
+	if len(names) == 0 {
+		for name := range info.Tables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return &Schema{}, nil
+	}
+
+	statements := make([]string, len(names))
+	infos := make([]struct {
+		Fields map[string]string `json:"fields"`
+	}, len(names))
+	destinations := make([]any, len(names))
+	for i, name := range names {
+		if _, ok := info.Tables[name]; !ok {
+			return nil, fmt.Errorf("table %s is not defined", name)
+		}
+		statements[i] = "INFO FOR TABLE " + surreal.EscapeIdent(name)
+		destinations[i] = &infos[i]
+	}
+	if err := db.QueryContext(ctx, strings.Join(statements, "; "), nil, destinations...); err != nil {
+		return nil, fmt.Errorf("failed to read table info: %w", err)
+	}
+
+	schema := &Schema{Tables: make([]Table, len(names))}
+	for i, name := range names {
+		table := Table{Name: name, Relation: isRelation(info.Tables[name])}
+		for _, definition := range infos[i].Fields {
+			field, err := ParseField(definition)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse field of %s: %w", name, err)
+			}
+			table.Fields = append(table.Fields, field)
+		}
+		sort.Slice(table.Fields, func(a, b int) bool {
+			return strings.Join(table.Fields[a].Path, ".") < strings.Join(table.Fields[b].Path, ".")
+		})
+		schema.Tables[i] = table
+	}
+
+	return schema, nil
+}
+
+// isRelation tells whether a DEFINE TABLE statement defines a relation table.
+func isRelation(definition string) bool {
+	words := splitWords(definition)
+	for i := 0; i+1 < len(words); i++ {
+		if strings.EqualFold(words[i], "TYPE") && strings.EqualFold(words[i+1], "RELATION") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,275 @@
+// Package surrealotel instruments surreal connections with OpenTelemetry traces and metrics.
+//
+// Instrument hooks into the options a connection is about to be established with:
+//
+//	options := &surreal.Options{}
+//	if err := surrealotel.Instrument(options, surrealotel.WithTracerProvider(tp), surrealotel.WithMeterProvider(mp)); err != nil {
+//		return err
+//	}
+//	db, err := surreal.Connect("ws://localhost:8000/rpc", options)
+//
+// Every call gets a client span named after its RPC method, carrying the namespace and database selected with Use
+// and, for queries, the statement text along with the time the server reported spending on it. Metrics cover the
+// calls in flight, their duration, reconnects and the live notifications received and dropped.
+package surrealotel
+
+import (
+	"context"
+	"errors"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"time"
+)
+
+// ScopeName is the instrumentation scope the tracer and meter are obtained with.
+const ScopeName = "github.com/terawatthour/surreal-go/surrealotel"
+
+// Attribute keys set on spans and metrics.
+const (
+	SystemKey         = attribute.Key("db.system")
+	OperationKey      = attribute.Key("db.operation.name")
+	NamespaceKey      = attribute.Key("db.surrealdb.namespace")
+	DatabaseKey       = attribute.Key("db.surrealdb.database")
+	StatementKey      = attribute.Key("db.query.text")
+	StatementCountKey = attribute.Key("db.surrealdb.statement_count")
+	ServerDurationKey = attribute.Key("db.surrealdb.server_duration")
+	ErrorTypeKey      = attribute.Key("error.type")
+	ActionKey         = attribute.Key("db.surrealdb.live.action")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	redact         func(statement string) string
+}
+
+// Option configures Instrument.
+type Option func(*config)
+
+// WithTracerProvider sets the provider spans are created with. Defaults to the global one.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the provider metrics are recorded with. Defaults to the global one.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithStatementRedactor passes statement text through redact before it is attached to spans, e.g. to strip
+// literals. Returning an empty string leaves the statement out.
+func WithStatementRedactor(redact func(statement string) string) Option {
+	return func(c *config) {
+		c.redact = redact
+	}
+}
+
+type instrumentation struct {
+	config
+	encoding surreal.Encoding
+	tracer   trace.Tracer
+
+	inFlight      metric.Int64UpDownCounter
+	duration      metric.Float64Histogram
+	reconnects    metric.Int64Counter
+	notifications metric.Int64Counter
+	dropped       metric.Int64Counter
+
+	lock      sync.RWMutex
+	namespace string
+	database  string
+}
+
+// Instrument adds an interceptor tracing and measuring calls to options, the outermost one, and chains callbacks
+// counting reconnects and live notifications onto those already set. A connection established with options is
+// instrumented; options must not be shared by connections that select different namespaces or databases.
+func Instrument(options *surreal.Options, opts ...Option) error {
+	i := &instrumentation{
+		config: config{
+			tracerProvider: otel.GetTracerProvider(),
+			meterProvider:  otel.GetMeterProvider(),
+		},
+		encoding: options.Encoding,
+	}
+	for _, opt := range opts {
+		opt(&i.config)
+	}
+
+	i.tracer = i.tracerProvider.Tracer(ScopeName)
+	if err := i.createInstruments(i.meterProvider.Meter(ScopeName)); err != nil {
+		return err
+	}
+
+	options.Interceptors = append([]surreal.Interceptor{i.intercept}, options.Interceptors...)
+
+	onReconnect := options.WebSocketOptions.OnReconnectCallback
+	options.WebSocketOptions.OnReconnectCallback = func(err error) {
+		i.reconnects.Add(context.Background(), 1, metric.WithAttributes(errorType(err)...))
+		if onReconnect != nil {
+			onReconnect(err)
+		}
+	}
+
+	onNotification := options.OnNotificationCallback
+	options.OnNotificationCallback = func(notification rpc.LiveNotification) {
+		i.notifications.Add(context.Background(), 1, metric.WithAttributes(ActionKey.String(string(notification.Action))))
+		if onNotification != nil {
+			onNotification(notification)
+		}
+	}
+
+	onDropped := options.OnNotificationDroppedCallback
+	options.OnNotificationDroppedCallback = func(id string) {
+		i.dropped.Add(context.Background(), 1)
+		if onDropped != nil {
+			onDropped(id)
+		}
+	}
+
+	return nil
+}
+
+func (i *instrumentation) createInstruments(meter metric.Meter) error {
+	var err, errs error
+
+	i.inFlight, err = meter.Int64UpDownCounter("surrealdb.client.requests.in_flight",
+		metric.WithDescription("Calls waiting for their response."),
+		metric.WithUnit("{call}"))
+	errs = errors.Join(errs, err)
+
+	i.duration, err = meter.Float64Histogram("surrealdb.client.request.duration",
+		metric.WithDescription("Duration of calls, from sending to receiving the response."),
+		metric.WithUnit("s"))
+	errs = errors.Join(errs, err)
+
+	i.reconnects, err = meter.Int64Counter("surrealdb.client.reconnects",
+		metric.WithDescription("Attempts to re-establish dropped connections, labelled with error.type if they failed."),
+		metric.WithUnit("{reconnect}"))
+	errs = errors.Join(errs, err)
+
+	i.notifications, err = meter.Int64Counter("surrealdb.client.live.notifications",
+		metric.WithDescription("Live notifications received."),
+		metric.WithUnit("{notification}"))
+	errs = errors.Join(errs, err)
+
+	i.dropped, err = meter.Int64Counter("surrealdb.client.live.dropped",
+		metric.WithDescription("Live notifications discarded by subscriptions with a full buffer."),
+		metric.WithUnit("{notification}"))
+	errs = errors.Join(errs, err)
+
+	return errs
+}
+
+func (i *instrumentation) intercept(ctx context.Context, method string, params []any, next surreal.Invoker) ([]byte, error) {
+	i.lock.RLock()
+	namespace, database := i.namespace, i.database
+	i.lock.RUnlock()
+
+	attributes := []attribute.KeyValue{SystemKey.String("surrealdb"), OperationKey.String(method)}
+	spanAttributes := attributes
+	if namespace != "" {
+		spanAttributes = append(spanAttributes, NamespaceKey.String(namespace), DatabaseKey.String(database))
+	}
+	if method == "query" && len(params) != 0 {
+		if statement, ok := params[0].(string); ok {
+			if i.redact != nil {
+				statement = i.redact(statement)
+			}
+			if statement != "" {
+				spanAttributes = append(spanAttributes, StatementKey.String(statement))
+			}
+		}
+	}
+
+	ctx, span := i.tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(spanAttributes...))
+	defer span.End()
+
+	i.inFlight.Add(ctx, 1, metric.WithAttributes(attributes...))
+	start := time.Now()
+
+	result, err := next(ctx, method, params)
+
+	i.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(append(attributes, errorType(err)...)...))
+	i.inFlight.Add(ctx, -1, metric.WithAttributes(attributes...))
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return result, err
+	}
+
+	switch method {
+	case "use":
+		if len(params) == 2 {
+			i.lock.Lock()
+			i.namespace, _ = params[0].(string)
+			i.database, _ = params[1].(string)
+			i.lock.Unlock()
+		}
+	case "query":
+		i.annotateQuery(span, result)
+	}
+
+	return result, nil
+}
+
+// annotateQuery adds the time the server spent on the statements of a query to its span, and marks the span failed
+// if any of them failed.
+func (i *instrumentation) annotateQuery(span trace.Span, result []byte) {
+	var statements rpc.RawResult
+	if err := i.encoding.Unmarshal(result, &statements); err != nil {
+		return
+	}
+
+	var serverDuration time.Duration
+	failed := 0
+	for _, statement := range statements {
+		serverDuration += time.Duration(statement.Time)
+		if !statement.OK {
+			failed++
+		}
+	}
+
+	span.SetAttributes(StatementCountKey.Int(len(statements)), ServerDurationKey.Float64(serverDuration.Seconds()))
+	if failed != 0 {
+		span.SetStatus(codes.Error, "query statement failed")
+	}
+}
+
+// errorType classifies err by the sentinel errors of the surreal package.
+func errorType(err error) []attribute.KeyValue {
+	if err == nil {
+		return nil
+	}
+
+	kinds := []struct {
+		sentinel error
+		name     string
+	}{
+		{surreal.ErrNotFound, "not_found"},
+		{surreal.ErrTimeout, "timeout"},
+		{surreal.ErrClosed, "closed"},
+		{surreal.ErrAuth, "auth"},
+		{surreal.ErrPermission, "permission"},
+		{surreal.ErrTxConflict, "tx_conflict"},
+		{surreal.ErrAlreadyExists, "already_exists"},
+		{context.Canceled, "canceled"},
+		{context.DeadlineExceeded, "deadline_exceeded"},
+	}
+	for _, kind := range kinds {
+		if errors.Is(err, kind.sentinel) {
+			return []attribute.KeyValue{ErrorTypeKey.String(kind.name)}
+		}
+	}
+	return []attribute.KeyValue{ErrorTypeKey.String("other")}
+}
@@ -0,0 +1,236 @@
+// Package surrealtest provides an in-process stand-in for a SurrealDB server, so that code using the driver can be
+// tested without a running database.
+//
+// The server speaks the JSON flavour of the RPC protocol over websockets and keeps records in memory. It implements
+// select, create, insert, update, upsert, merge, patch, delete, relate, live and kill on its own and accepts the
+// session calls (use, let, signin, ...) without enforcing them. It does not parse SurrealQL, query calls fail unless
+// scripted with Handle.
+package surrealtest
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"github.com/terawatthour/surreal-go/rpc"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Version is what the server answers version calls with.
+const Version = "surrealdb-surrealtest"
+
+// Token is the session token signin and signup calls are answered with.
+const Token = "surrealtest-token"
+
+// Handler answers an RPC call in place of the server's own implementation.
+type Handler func(params []any) (result any, err *rpc.Error)
+
+type Server struct {
+	// URL is the websocket url of the server's /rpc endpoint, to be passed to surreal.Connect.
+	URL string
+
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	lock     sync.Mutex
+	tables   map[string]*table
+	lives    map[string]*liveQuery
+	handlers map[string]Handler
+	failures map[string][]*rpc.Error
+	calls    []rpc.Outgoing
+	conns    map[*conn]struct{}
+}
+
+// conn is a client connected to the server.
+type conn struct {
+	ws   *websocket.Conn
+	lock sync.Mutex
+}
+
+func (c *conn) write(message any) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.ws.WriteJSON(message)
+}
+
+// NewServer starts a server listening on a local port. It should be closed with Close.
+func NewServer() *Server {
+	s := &Server{
+		upgrader: websocket.Upgrader{Subprotocols: []string{"json"}},
+		tables:   make(map[string]*table),
+		lives:    make(map[string]*liveQuery),
+		handlers: make(map[string]Handler),
+		failures: make(map[string][]*rpc.Error),
+		conns:    make(map[*conn]struct{}),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	s.URL = "ws" + strings.TrimPrefix(s.server.URL, "http") + "/rpc"
+
+	return s
+}
+
+// Close disconnects all clients and shuts the server down.
+func (s *Server) Close() {
+	s.DropConnections()
+	s.server.Close()
+}
+
+// Handle scripts the responses to method, overriding the server's own implementation. A nil handler restores it.
+func (s *Server) Handle(method string, handler Handler) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if handler == nil {
+		delete(s.handlers, method)
+		return
+	}
+	s.handlers[method] = handler
+}
+
+// Fail makes the next call of method fail with err. Failures queued for the same method are used up in order.
+func (s *Server) Fail(method string, err *rpc.Error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.failures[method] = append(s.failures[method], err)
+}
+
+// DropConnections closes the sockets of all connected clients, without a close handshake, as if the network failed.
+func (s *Server) DropConnections() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for c := range s.conns {
+		_ = c.ws.Close()
+		delete(s.conns, c)
+	}
+}
+
+// Calls returns the calls received so far, in order.
+func (s *Server) Calls() []rpc.Outgoing {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]rpc.Outgoing(nil), s.calls...)
+}
+
+// QueryResult builds the response to a query call whose statements returned results, for use in handlers.
+func QueryResult(results ...any) []map[string]any {
+	response := make([]map[string]any, len(results))
+	for i, result := range results {
+		response[i] = map[string]any{"status": "OK", "time": "0s", "result": result}
+	}
+	return response
+}
+
+// QueryError builds the result of a failed statement, to be mixed into the response of a query call.
+func QueryError(message string) map[string]any {
+	return map[string]any{"status": "ERR", "time": "0s", "result": message}
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &conn{ws: ws}
+	s.lock.Lock()
+	s.conns[c] = struct{}{}
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, c)
+		for id, live := range s.lives {
+			if live.conn == c {
+				delete(s.lives, id)
+			}
+		}
+		s.lock.Unlock()
+		_ = ws.Close()
+	}()
+
+	for {
+		_, msg, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var outgoing rpc.Outgoing
+		if err := json.Unmarshal(msg, &outgoing); err != nil {
+			_ = c.write(map[string]any{"id": nil, "error": &rpc.Error{Code: -32700, Message: "Parse error"}})
+			continue
+		}
+
+		result, rpcErr := s.call(c, outgoing)
+		if rpcErr != nil {
+			_ = c.write(map[string]any{"id": outgoing.ID, "error": rpcErr})
+			continue
+		}
+		_ = c.write(map[string]any{"id": outgoing.ID, "result": result})
+	}
+}
+
+func (s *Server) call(c *conn, outgoing rpc.Outgoing) (any, *rpc.Error) {
+	s.lock.Lock()
+	s.calls = append(s.calls, outgoing)
+
+	if failures := s.failures[outgoing.Method]; len(failures) != 0 {
+		s.failures[outgoing.Method] = failures[1:]
+		s.lock.Unlock()
+		return nil, failures[0]
+	}
+
+	if handler, ok := s.handlers[outgoing.Method]; ok {
+		s.lock.Unlock()
+		return handler(outgoing.Params)
+	}
+
+	defer s.lock.Unlock()
+
+	params := outgoing.Params
+	switch outgoing.Method {
+	case "use", "let", "unset", "authenticate", "invalidate", "ping", "info":
+		return nil, nil
+	case "signin", "signup":
+		return Token, nil
+	case "version":
+		return Version, nil
+	case "select":
+		return s.selectRecords(param(params, 0))
+	case "create":
+		return s.create(param(params, 0), param(params, 1))
+	case "insert":
+		return s.insert(param(params, 0), param(params, 1))
+	case "update", "upsert":
+		return s.update(param(params, 0), param(params, 1))
+	case "merge":
+		return s.merge(param(params, 0), param(params, 1))
+	case "patch":
+		diff, _ := param(params, 2).(bool)
+		return s.patch(param(params, 0), param(params, 1), diff)
+	case "delete":
+		return s.delete(param(params, 0))
+	case "relate":
+		return s.relate(param(params, 0), param(params, 1), param(params, 2), param(params, 3))
+	case "live":
+		diff, _ := param(params, 1).(bool)
+		return s.live(c, param(params, 0), diff)
+	case "kill":
+		return s.kill(param(params, 0))
+	case "query":
+		return nil, &rpc.Error{Code: -32000, Message: "surrealtest does not run SurrealQL, script query calls with Handle"}
+	default:
+		return nil, &rpc.Error{Code: -32601, Message: "Method not found"}
+	}
+}
+
+func param(params []any, i int) any {
+	if i < len(params) {
+		return params[i]
+	}
+	return nil
+}
@@ -0,0 +1,592 @@
+package surrealtest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"reflect"
+	"strings"
+)
+
+// table keeps the records of a table in the order they were created.
+type table struct {
+	keys    []string
+	records map[string]map[string]any
+}
+
+type liveQuery struct {
+	conn  *conn
+	table string
+	diff  bool
+}
+
+// Put stores a record under id, a record id like `article:1`, replacing any record stored there before.
+func (s *Server) Put(id string, record map[string]any) error {
+	rid, err := surreal.ParseRecordID(id)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.store(rid, record)
+	return nil
+}
+
+// Get returns the record stored under id.
+func (s *Server) Get(id string) (map[string]any, bool) {
+	rid, err := surreal.ParseRecordID(id)
+	if err != nil {
+		return nil, false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	record, ok := s.lookup(rid)
+	return clone(record), ok
+}
+
+// Records returns the records of a table in the order they were created.
+func (s *Server) Records(name string) []map[string]any {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t, ok := s.tables[name]
+	if !ok {
+		return nil
+	}
+
+	records := make([]map[string]any, len(t.keys))
+	for i, key := range t.keys {
+		records[i] = clone(t.records[key])
+	}
+	return records
+}
+
+// target is what a call is aimed at, either a whole table or a single record.
+type target struct {
+	table string
+	id    *surreal.RecordID
+}
+
+func resolve(what any) (target, *rpc.Error) {
+	s, ok := what.(string)
+	if !ok || s == "" {
+		return target{}, invalidParams()
+	}
+
+	if strings.ContainsAny(s, ":⟨`") {
+		rid, err := surreal.ParseRecordID(s)
+		if err != nil {
+			return target{}, &rpc.Error{Code: -32603, Message: fmt.Sprintf("Invalid params: %s", err)}
+		}
+		return target{table: rid.Table, id: &rid}, nil
+	}
+
+	return target{table: s}, nil
+}
+
+func invalidParams() *rpc.Error {
+	return &rpc.Error{Code: -32603, Message: "Invalid params"}
+}
+
+func databaseError(format string, args ...any) *rpc.Error {
+	return &rpc.Error{Code: -32000, Message: "There was a problem with the database: " + fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) lookup(rid surreal.RecordID) (map[string]any, bool) {
+	t, ok := s.tables[rid.Table]
+	if !ok {
+		return nil, false
+	}
+	record, ok := t.records[rid.String()]
+	return record, ok
+}
+
+// store saves record under rid, setting its id field, and returns the stored copy.
+func (s *Server) store(rid surreal.RecordID, record map[string]any) map[string]any {
+	t, ok := s.tables[rid.Table]
+	if !ok {
+		t = &table{records: make(map[string]map[string]any)}
+		s.tables[rid.Table] = t
+	}
+
+	key := rid.String()
+	if _, exists := t.records[key]; !exists {
+		t.keys = append(t.keys, key)
+	}
+
+	stored := clone(record)
+	if stored == nil {
+		stored = make(map[string]any)
+	}
+	stored["id"] = key
+	t.records[key] = stored
+
+	return clone(stored)
+}
+
+func (s *Server) remove(rid surreal.RecordID) {
+	t, ok := s.tables[rid.Table]
+	if !ok {
+		return
+	}
+
+	key := rid.String()
+	delete(t.records, key)
+	for i, k := range t.keys {
+		if k == key {
+			t.keys = append(t.keys[:i], t.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// each calls fn with the id and a copy of every record the target covers, in order.
+func (s *Server) each(tg target, fn func(rid surreal.RecordID, record map[string]any)) {
+	if tg.id != nil {
+		if record, ok := s.lookup(*tg.id); ok {
+			fn(*tg.id, clone(record))
+		}
+		return
+	}
+
+	t, ok := s.tables[tg.table]
+	if !ok {
+		return
+	}
+	for _, key := range append([]string(nil), t.keys...) {
+		rid, _ := surreal.ParseRecordID(key)
+		fn(rid, clone(t.records[key]))
+	}
+}
+
+// respond shapes the records a call produced the way the server does: a single record for record targets, a list for
+// tables.
+func respond(tg target, records []map[string]any) any {
+	if tg.id != nil {
+		if len(records) == 0 {
+			return nil
+		}
+		return records[0]
+	}
+	if records == nil {
+		return []map[string]any{}
+	}
+	return records
+}
+
+func (s *Server) selectRecords(what any) (any, *rpc.Error) {
+	tg, err := resolve(what)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []map[string]any
+	s.each(tg, func(_ surreal.RecordID, record map[string]any) {
+		records = append(records, record)
+	})
+	return respond(tg, records), nil
+}
+
+func (s *Server) create(what any, data any) (any, *rpc.Error) {
+	tg, err := resolve(what)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := object(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rid := surreal.NewRecordID(tg.table, generateKey())
+	if tg.id != nil {
+		rid = *tg.id
+		if _, exists := s.lookup(rid); exists {
+			return nil, databaseError("Database record `%s` already exists", rid)
+		}
+	}
+
+	record := s.store(rid, content)
+	s.notify(rid.Table, rpc.Create, rid, nil, record)
+
+	return respond(tg, []map[string]any{record}), nil
+}
+
+func (s *Server) insert(what any, data any) (any, *rpc.Error) {
+	name, ok := what.(string)
+	if !ok {
+		return nil, invalidParams()
+	}
+
+	var items []any
+	switch data := data.(type) {
+	case []any:
+		items = data
+	default:
+		items = []any{data}
+	}
+
+	contents := make([]map[string]any, len(items))
+	rids := make([]surreal.RecordID, len(items))
+	for i, item := range items {
+		content, err := object(item)
+		if err != nil {
+			return nil, err
+		}
+
+		rids[i] = surreal.NewRecordID(name, generateKey())
+		if id, ok := content["id"].(string); ok && id != "" {
+			if strings.HasPrefix(id, name+":") {
+				rid, err := surreal.ParseRecordID(id)
+				if err != nil {
+					return nil, &rpc.Error{Code: -32603, Message: fmt.Sprintf("Invalid params: %s", err)}
+				}
+				rids[i] = rid
+			} else {
+				rids[i] = surreal.NewRecordID(name, id)
+			}
+		}
+		if _, exists := s.lookup(rids[i]); exists {
+			return nil, databaseError("Database record `%s` already exists", rids[i])
+		}
+		contents[i] = content
+	}
+
+	records := make([]map[string]any, len(items))
+	for i, content := range contents {
+		records[i] = s.store(rids[i], content)
+		s.notify(name, rpc.Create, rids[i], nil, records[i])
+	}
+
+	return records, nil
+}
+
+func (s *Server) update(what any, data any) (any, *rpc.Error) {
+	return s.modify(what, data, func(_ map[string]any, content map[string]any) map[string]any {
+		return content
+	})
+}
+
+func (s *Server) merge(what any, data any) (any, *rpc.Error) {
+	return s.modify(what, data, func(record map[string]any, content map[string]any) map[string]any {
+		return mergeObjects(record, content)
+	})
+}
+
+// modify replaces the records the target covers with what change returns for them. Records targeted by id are created
+// if they do not exist yet.
+func (s *Server) modify(what any, data any, change func(record, content map[string]any) map[string]any) (any, *rpc.Error) {
+	tg, err := resolve(what)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := object(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if tg.id != nil {
+		record, exists := s.lookup(*tg.id)
+		if !exists {
+			stored := s.store(*tg.id, change(nil, content))
+			s.notify(tg.table, rpc.Create, *tg.id, nil, stored)
+			return stored, nil
+		}
+		if data == nil {
+			return clone(record), nil
+		}
+	}
+
+	var records []map[string]any
+	s.each(tg, func(rid surreal.RecordID, record map[string]any) {
+		if data == nil {
+			records = append(records, record)
+			return
+		}
+		stored := s.store(rid, change(record, content))
+		s.notify(tg.table, rpc.Update, rid, record, stored)
+		records = append(records, stored)
+	})
+
+	return respond(tg, records), nil
+}
+
+func (s *Server) patch(what any, data any, diff bool) (any, *rpc.Error) {
+	tg, err := resolve(what)
+	if err != nil {
+		return nil, err
+	}
+
+	operations, ok := data.([]any)
+	if !ok {
+		return nil, invalidParams()
+	}
+
+	var records []map[string]any
+	var diffs []any
+	var failure *rpc.Error
+	s.each(tg, func(rid surreal.RecordID, record map[string]any) {
+		if failure != nil {
+			return
+		}
+
+		patched := clone(record)
+		for _, operation := range operations {
+			if failure = applyPatch(patched, operation); failure != nil {
+				return
+			}
+		}
+
+		stored := s.store(rid, patched)
+		s.notify(tg.table, rpc.Update, rid, record, stored)
+		records = append(records, stored)
+		diffs = append(diffs, diffObjects(record, stored))
+	})
+	if failure != nil {
+		return nil, failure
+	}
+
+	if diff {
+		if tg.id != nil && len(diffs) == 1 {
+			return diffs[0], nil
+		}
+		return diffs, nil
+	}
+	return respond(tg, records), nil
+}
+
+func (s *Server) delete(what any) (any, *rpc.Error) {
+	tg, err := resolve(what)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []map[string]any
+	s.each(tg, func(rid surreal.RecordID, record map[string]any) {
+		s.remove(rid)
+		s.notify(tg.table, rpc.Delete, rid, record, record)
+		records = append(records, record)
+	})
+
+	return respond(tg, records), nil
+}
+
+func (s *Server) relate(from any, thing any, to any, data any) (any, *rpc.Error) {
+	in, err := resolve(from)
+	if err != nil || in.id == nil {
+		return nil, invalidParams()
+	}
+	out, err := resolve(to)
+	if err != nil || out.id == nil {
+		return nil, invalidParams()
+	}
+
+	content, err := object(data)
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		content = make(map[string]any)
+	}
+	content["in"] = in.id.String()
+	content["out"] = out.id.String()
+
+	edge, err := resolve(thing)
+	if err != nil {
+		return nil, err
+	}
+	rid := surreal.NewRecordID(edge.table, generateKey())
+	if edge.id != nil {
+		rid = *edge.id
+	}
+
+	record := s.store(rid, content)
+	s.notify(rid.Table, rpc.Create, rid, nil, record)
+
+	return record, nil
+}
+
+func (s *Server) live(c *conn, what any, diff bool) (any, *rpc.Error) {
+	name, ok := what.(string)
+	if !ok {
+		return nil, invalidParams()
+	}
+
+	id := generateUUID()
+	s.lives[id] = &liveQuery{conn: c, table: name, diff: diff}
+
+	return id, nil
+}
+
+func (s *Server) kill(what any) (any, *rpc.Error) {
+	id, ok := what.(string)
+	if !ok {
+		return nil, invalidParams()
+	}
+	if _, exists := s.lives[id]; !exists {
+		return nil, databaseError("Can not execute KILL statement using id '%s'", id)
+	}
+
+	delete(s.lives, id)
+	return nil, nil
+}
+
+// notify sends a notification to every live query on the table of a changed record.
+func (s *Server) notify(name string, action rpc.Action, rid surreal.RecordID, before, after map[string]any) {
+	for id, live := range s.lives {
+		if live.table != name {
+			continue
+		}
+
+		var result any = after
+		if live.diff && action != rpc.Delete {
+			result = diffObjects(before, after)
+		}
+
+		_ = live.conn.write(map[string]any{"result": map[string]any{
+			"id":     id,
+			"action": action,
+			"record": rid.String(),
+			"result": result,
+		}})
+	}
+}
+
+// object checks that data is an object, or nothing at all.
+func object(data any) (map[string]any, *rpc.Error) {
+	switch data := data.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return clone(data), nil
+	default:
+		return nil, invalidParams()
+	}
+}
+
+func mergeObjects(record, content map[string]any) map[string]any {
+	merged := clone(record)
+	if merged == nil {
+		merged = make(map[string]any)
+	}
+
+	for key, value := range content {
+		existing, isObject := merged[key].(map[string]any)
+		incoming, mergeable := value.(map[string]any)
+		if isObject && mergeable {
+			merged[key] = mergeObjects(existing, incoming)
+			continue
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// applyPatch applies a single JSON Patch operation with a path into nested objects.
+func applyPatch(record map[string]any, operation any) *rpc.Error {
+	op, ok := operation.(map[string]any)
+	if !ok {
+		return invalidParams()
+	}
+
+	kind, _ := op["op"].(string)
+	path, _ := op["path"].(string)
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if path == "" || segments[0] == "" {
+		return invalidParams()
+	}
+
+	parent := record
+	for _, segment := range segments[:len(segments)-1] {
+		child, ok := parent[segment].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			parent[segment] = child
+		}
+		parent = child
+	}
+
+	key := segments[len(segments)-1]
+	switch kind {
+	case "add", "replace":
+		parent[key] = op["value"]
+	case "remove":
+		delete(parent, key)
+	default:
+		return &rpc.Error{Code: -32603, Message: fmt.Sprintf("Invalid params: unsupported patch operation %q", kind)}
+	}
+
+	return nil
+}
+
+// diffObjects describes the change from before to after as top-level JSON Patch operations.
+func diffObjects(before, after map[string]any) []map[string]any {
+	operations := []map[string]any{}
+	if before == nil {
+		return append(operations, map[string]any{"op": "replace", "path": "/", "value": after})
+	}
+
+	for key, value := range after {
+		previous, existed := before[key]
+		switch {
+		case !existed:
+			operations = append(operations, map[string]any{"op": "add", "path": "/" + key, "value": value})
+		case !reflect.DeepEqual(previous, value):
+			operations = append(operations, map[string]any{"op": "replace", "path": "/" + key, "value": value})
+		}
+	}
+	for key := range before {
+		if _, exists := after[key]; !exists {
+			operations = append(operations, map[string]any{"op": "remove", "path": "/" + key})
+		}
+	}
+
+	return operations
+}
+
+// clone copies a record deeply enough that callers can not alter what is stored.
+func clone(record map[string]any) map[string]any {
+	if record == nil {
+		return nil
+	}
+
+	copied := make(map[string]any, len(record))
+	for key, value := range record {
+		if nested, ok := value.(map[string]any); ok {
+			value = clone(nested)
+		}
+		copied[key] = value
+	}
+	return copied
+}
+
+// generateKey makes up a record key like the server's, starting with a letter so that it is never escaped.
+func generateKey() string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	var b [20]byte
+	_, _ = rand.Read(b[:])
+	for i := range b {
+		if i == 0 {
+			b[i] = alphabet[int(b[i])%26]
+			continue
+		}
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b[:])
+}
+
+func generateUUID() string {
+	var u surreal.UUID
+	_, _ = rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return u.String()
+}
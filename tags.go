@@ -0,0 +1,498 @@
+package surreal
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/terawatthour/surreal-go/rpc"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Struct fields may be tagged `surreal:"name,option,..."` to control how they are exchanged with the server. Fields
+// without a surreal tag fall back to their json tag. The options are:
+//
+//   - omitempty leaves the field out, so that it is NONE rather than NULL, when it holds its zero value.
+//   - readonly is never sent, e.g. for the id of a record or fields computed by the server. It is still decoded.
+//   - recordid marks a string field holding a record id, which is sent as a record id rather than a string.
+//   - fetch marks a field linking to another record. A struct is sent as the id of the record it holds. When decoding,
+//     a fetched record is decoded into a struct, or only its id into a RecordID or string, while a record id that was
+//     not fetched is decoded into the id field of a struct.
+//
+// Types whose structs have no surreal tags are encoded and decoded as they would be without this package.
+
+type fieldInfo struct {
+	name      string
+	index     []int
+	omitempty bool
+	readonly  bool
+	recordid  bool
+	fetch     bool
+}
+
+type structInfo struct {
+	fields []fieldInfo
+	// id is the field holding the record id, named id or tagged recordid, or nil
+	id *fieldInfo
+}
+
+var (
+	jsonMarshaler   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	cborMarshaler   = reflect.TypeOf((*cbor.Marshaler)(nil)).Elem()
+	cborUnmarshaler = reflect.TypeOf((*cbor.Unmarshaler)(nil)).Elem()
+)
+
+var (
+	structInfos sync.Map // reflect.Type -> *structInfo
+	taggedTypes sync.Map // reflect.Type -> bool
+)
+
+// customMarshaling reports types that take care of their own encoding or decoding, which tags do not apply to.
+func customMarshaling(t reflect.Type) bool {
+	for _, i := range []reflect.Type{jsonMarshaler, jsonUnmarshaler, cborMarshaler, cborUnmarshaler} {
+		if t.Implements(i) || reflect.PointerTo(t).Implements(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagged reports whether values of t may contain structs with surreal tags, judging by t alone.
+func tagged(t reflect.Type) bool {
+	if known, ok := taggedTypes.Load(t); ok {
+		return known.(bool)
+	}
+	result, _ := taggedVisit(t, map[reflect.Type]int{})
+	return result
+}
+
+// taggedVisit does the work of tagged, with visiting holding the depth of each type in progress on the current path.
+// A type reached again while in progress is assumed untagged, which ends the recursion of self-referencing types. It
+// also reports the lowest depth such an assumption was made at, as a result relying on the assumption about an outer
+// type is only known once that type is done, and must not be cached before.
+func taggedVisit(t reflect.Type, visiting map[reflect.Type]int) (bool, int) {
+	if known, ok := taggedTypes.Load(t); ok {
+		return known.(bool), math.MaxInt
+	}
+	if depth, ok := visiting[t]; ok {
+		return false, depth
+	}
+
+	depth := len(visiting)
+	visiting[t] = depth
+	defer delete(visiting, t)
+
+	result, lowest := false, math.MaxInt
+	visit := func(t reflect.Type) bool {
+		tagged, low := taggedVisit(t, visiting)
+		lowest = min(lowest, low)
+		return tagged
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		result = visit(t.Elem())
+	case reflect.Struct:
+		if customMarshaling(t) {
+			break
+		}
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if _, ok := field.Tag.Lookup("surreal"); ok || (field.IsExported() && visit(field.Type)) {
+				result = true
+				break
+			}
+		}
+	}
+
+	if result || lowest >= depth {
+		taggedTypes.Store(t, result)
+		return result, math.MaxInt
+	}
+	return false, lowest
+}
+
+func getStructInfo(t reflect.Type) *structInfo {
+	if info, ok := structInfos.Load(t); ok {
+		return info.(*structInfo)
+	}
+
+	info := &structInfo{}
+	collectFields(t, nil, info)
+	for i := range info.fields {
+		if info.fields[i].recordid || (info.id == nil && info.fields[i].name == "id") {
+			info.id = &info.fields[i]
+		}
+	}
+
+	structInfos.Store(t, info)
+	return info
+}
+
+func collectFields(t reflect.Type, index []int, info *structInfo) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		tag, ok := field.Tag.Lookup("surreal")
+		if !ok {
+			tag = field.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			collectFields(field.Type, fieldIndex, info)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		f := fieldInfo{name: name, index: fieldIndex}
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "omitempty":
+				f.omitempty = true
+			case "readonly":
+				f.readonly = true
+			case "recordid":
+				f.recordid = true
+			case "fetch":
+				f.fetch = true
+			}
+		}
+		info.fields = append(info.fields, f)
+	}
+}
+
+// encodeParams prepares the params of a call for encoding, applying the surreal tags of the structs they hold.
+func encodeParams(params []any) []any {
+	encoded, changed := encodeValue(reflect.ValueOf(params))
+	if !changed {
+		return params
+	}
+	return encoded.([]any)
+}
+
+// encodeValue replaces the structs with surreal tags within v by maps. Reports false, along with v itself, if there
+// was nothing to replace.
+func encodeValue(v reflect.Value) (any, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+
+	t := v.Type()
+	if t.Kind() != reflect.Interface && !tagged(t) && !containsInterfaces(t) {
+		return v.Interface(), false
+	}
+
+	switch t.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return v.Interface(), false
+		}
+		inner, changed := encodeValue(v.Elem())
+		if !changed {
+			return v.Interface(), false
+		}
+		return inner, true
+	case reflect.Struct:
+		if !tagged(t) {
+			return v.Interface(), false
+		}
+		return encodeStruct(v), true
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && v.IsNil() {
+			return v.Interface(), false
+		}
+
+		var encoded []any
+		for i := range v.Len() {
+			item, changed := encodeValue(v.Index(i))
+			if changed && encoded == nil {
+				encoded = make([]any, v.Len())
+				for j := range i {
+					encoded[j] = v.Index(j).Interface()
+				}
+			}
+			if encoded != nil {
+				encoded[i] = item
+			}
+		}
+		if encoded == nil {
+			return v.Interface(), false
+		}
+		return encoded, true
+	case reflect.Map:
+		if v.IsNil() || t.Key().Kind() != reflect.String {
+			return v.Interface(), false
+		}
+
+		var encoded Map
+		keys := v.MapKeys()
+		for i, key := range keys {
+			item, changed := encodeValue(v.MapIndex(key))
+			if changed && encoded == nil {
+				encoded = make(Map, v.Len())
+				for _, previous := range keys[:i] {
+					encoded[previous.String()] = v.MapIndex(previous).Interface()
+				}
+			}
+			if encoded != nil {
+				encoded[key.String()] = item
+			}
+		}
+		if encoded == nil {
+			return v.Interface(), false
+		}
+		return encoded, true
+	default:
+		return v.Interface(), false
+	}
+}
+
+// containsInterfaces reports whether values of t may hold values of other types, which have to be inspected one by
+// one.
+func containsInterfaces(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return containsInterfaces(t.Elem())
+	default:
+		return false
+	}
+}
+
+func encodeStruct(v reflect.Value) Map {
+	info := getStructInfo(v.Type())
+	encoded := make(Map, len(info.fields))
+
+	for _, field := range info.fields {
+		if field.readonly {
+			continue
+		}
+		value, ok := fieldByIndex(v, field.index)
+		if !ok || (field.omitempty && value.IsZero()) {
+			continue
+		}
+
+		switch {
+		case field.recordid && value.Kind() == reflect.String:
+			if id, err := ParseRecordID(value.String()); err == nil {
+				encoded[field.name] = id
+				continue
+			}
+		case field.fetch:
+			if id, ok := linkedID(value); ok {
+				encoded[field.name] = id
+				continue
+			}
+		}
+
+		item, _ := encodeValue(value)
+		encoded[field.name] = item
+	}
+
+	return encoded
+}
+
+// linkedID returns the record id held by the id field of a struct, or of the struct a pointer points to.
+func linkedID(v reflect.Value) (any, bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || customMarshaling(v.Type()) {
+		return nil, false
+	}
+
+	info := getStructInfo(v.Type())
+	if info.id == nil {
+		return nil, false
+	}
+	id, ok := fieldByIndex(v, info.id.index)
+	if !ok || id.IsZero() {
+		return nil, false
+	}
+	if id.Kind() == reflect.String {
+		if parsed, err := ParseRecordID(id.String()); err == nil {
+			return parsed, true
+		}
+	}
+	return id.Interface(), true
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but reports false instead of panicking on nil embedded pointers.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// decode decodes raw into destination, which must be a pointer, applying the surreal tags of the structs it holds.
+func decode(c codec, raw []byte, destination any) error {
+	v := reflect.ValueOf(destination)
+	if v.Kind() != reflect.Pointer || v.IsNil() || !tagged(v.Type().Elem()) {
+		return c.unmarshal(raw, destination)
+	}
+	return decodeValue(c, raw, v.Elem())
+}
+
+func decodeValue(c codec, raw []byte, v reflect.Value) error {
+	t := v.Type()
+	if !tagged(t) {
+		return c.unmarshal(raw, v.Addr().Interface())
+	}
+
+	if c.shape(raw) == shapeNull {
+		v.SetZero()
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return decodeValue(c, raw, v.Elem())
+	case reflect.Slice:
+		var items []rpc.RawMessage
+		if err := c.unmarshal(raw, &items); err != nil {
+			return err
+		}
+		v.Set(reflect.MakeSlice(t, len(items), len(items)))
+		for i, item := range items {
+			if err := decodeValue(c, item, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Array:
+		var items []rpc.RawMessage
+		if err := c.unmarshal(raw, &items); err != nil {
+			return err
+		}
+		for i := 0; i < len(items) && i < v.Len(); i++ {
+			if err := decodeValue(c, items[i], v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return c.unmarshal(raw, v.Addr().Interface())
+		}
+		var items map[string]rpc.RawMessage
+		if err := c.unmarshal(raw, &items); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(t, len(items)))
+		}
+		for key, item := range items {
+			value := reflect.New(t.Elem()).Elem()
+			if err := decodeValue(c, item, value); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), value)
+		}
+		return nil
+	case reflect.Struct:
+		return decodeStruct(c, raw, v)
+	default:
+		return c.unmarshal(raw, v.Addr().Interface())
+	}
+}
+
+func decodeStruct(c codec, raw []byte, v reflect.Value) error {
+	info := getStructInfo(v.Type())
+
+	// a link that was not fetched only tells the id of the record
+	if c.shape(raw) != shapeObject {
+		if info.id == nil {
+			return fmt.Errorf("cannot decode a record link into %s, it has no id field", v.Type())
+		}
+		return decodeField(c, raw, v, *info.id)
+	}
+
+	var fields map[string]rpc.RawMessage
+	if err := c.unmarshal(raw, &fields); err != nil {
+		return err
+	}
+
+	for _, field := range info.fields {
+		item, ok := fields[field.name]
+		if !ok {
+			for name, candidate := range fields {
+				if strings.EqualFold(name, field.name) {
+					item, ok = candidate, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		if err := decodeField(c, item, v, field); err != nil {
+			return fmt.Errorf("failed to decode field %s: %w", field.name, err)
+		}
+	}
+
+	return nil
+}
+
+func decodeField(c codec, raw []byte, v reflect.Value, field fieldInfo) error {
+	value, ok := fieldByIndex(v, field.index)
+	if !ok {
+		return nil
+	}
+
+	// the record was fetched, while only its id is wanted
+	if field.fetch && c.shape(raw) == shapeObject && !structLike(value.Type()) {
+		var record struct {
+			ID rpc.RawMessage `json:"id"`
+		}
+		if err := c.unmarshal(raw, &record); err != nil {
+			return err
+		}
+		raw = record.ID
+	}
+
+	if (field.recordid || field.fetch) && value.Kind() == reflect.String {
+		var id RecordID
+		if err := c.unmarshal(raw, &id); err == nil {
+			value.SetString(id.String())
+			return nil
+		}
+	}
+
+	return decodeValue(c, raw, value)
+}
+
+// structLike reports whether t, or what it points to, is a struct that records can be decoded into.
+func structLike(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return (t.Kind() == reflect.Struct && !customMarshaling(t)) || t.Kind() == reflect.Map
+}
@@ -10,7 +10,7 @@ import (
 )
 
 func connect() *surreal.DB {
-	db, err := surreal.Connect("ws://localhost:8000/rpc", nil)
+	db, err := surreal.Connect(surrealURL, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -154,8 +154,8 @@ func TestPatch(t *testing.T) {
 	var patched []Article
 	// sets every article's createdAt to now, deletes updatedAt
 	if err := db.Patch("article", []surreal.Diff{
-		{"replace", "/createdAt", now},
-		{"replace", "/updatedAt", nil}}, &patched); err != nil {
+		{Op: "replace", Path: "/createdAt", Value: now},
+		{Op: "replace", Path: "/updatedAt", Value: nil}}, &patched); err != nil {
 		t.Fatal(err)
 	}
 	fmt.Println(patched)
@@ -169,7 +169,7 @@ func TestLive(t *testing.T) {
 		}
 	}()
 
-	received := false
+	received := make(chan struct{}, 1)
 	id, err := db.Live("article", func(payload rpc.LiveNotification) {
 		var diff []surreal.LiveDiff
 		if err := json.Unmarshal(payload.Result, &diff); err != nil {
@@ -177,7 +177,10 @@ func TestLive(t *testing.T) {
 			return
 		}
 
-		received = true
+		select {
+		case received <- struct{}{}:
+		default:
+		}
 
 		fmt.Println("article updated", diff)
 	}, true)
@@ -191,7 +194,9 @@ func TestLive(t *testing.T) {
 		Content: "This is the first article",
 	})
 
-	for !received {
-
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for live notification")
 	}
 }
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	batch := db.Batch()
+	created := make([]Article, 100)
+	for i := range created {
+		batch.Create(fmt.Sprintf("article:%d", i), Article{Title: fmt.Sprint(i)}, &created[i])
+	}
+	if err := batch.Wait(); err != nil {
+		t.Fatalf("unexpected Wait error: %s", err)
+	}
+	for i, article := range created {
+		if article.Title != fmt.Sprint(i) {
+			t.Fatalf("unexpected article %d: %+v", i, article)
+		}
+	}
+
+	for i, call := range server.Calls() {
+		if call.Params[0] != fmt.Sprintf("article:%d", i) {
+			t.Fatalf("expected calls to be sent in order, got %v at %d", call.Params[0], i)
+		}
+	}
+
+	server.Fail("create", &rpc.Error{Code: -32000, Message: "Database record `article:0` already exists"})
+	server.Handle("query", func(params []any) (any, *rpc.Error) {
+		return surrealtest.QueryResult(1), nil
+	})
+
+	batch = db.Batch()
+	var first Article
+	var missing Article
+	var n int
+	failed := batch.Create("article:0", Article{Title: "again"})
+	selected := batch.Select("article:0", &first)
+	notFound := batch.Select("article:missing", &missing)
+	queried := batch.Query("RETURN 1", nil, &n)
+	batch.Send()
+
+	if err := failed.Wait(); !errors.Is(err, surreal.ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+	if err := selected.Wait(); err != nil || first.Title != "0" {
+		t.Fatalf("unexpected select result %+v %v", first, err)
+	}
+	if err := notFound.Wait(); !errors.Is(err, surreal.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := queried.Wait(); err != nil || n != 1 {
+		t.Fatalf("unexpected query result %d %v", n, err)
+	}
+	if err := batch.Wait(); !errors.Is(err, surreal.ErrAlreadyExists) || !errors.Is(err, surreal.ErrNotFound) {
+		t.Fatalf("expected Wait to join the errors of the failed calls, got %v", err)
+	}
+}
+
+func TestPoolBatch(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	pool, err := surreal.ConnectPool(server.URL, &surreal.Options{PoolOptions: surreal.PoolOptions{Size: 3}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer pool.Close()
+
+	batch := pool.Batch()
+	for i := range 10 {
+		batch.Create(fmt.Sprintf("article:%d", i), Article{Title: fmt.Sprint(i)})
+	}
+	if err := batch.Wait(); err != nil {
+		t.Fatalf("unexpected Wait error: %s", err)
+	}
+
+	var articles []Article
+	if err := pool.Select("article", &articles); err != nil || len(articles) != 10 {
+		t.Fatalf("unexpected select result %+v %v", articles, err)
+	}
+}
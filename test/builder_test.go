@@ -0,0 +1,151 @@
+package test
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/builder"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		statement surreal.Statement
+		query     string
+		vars      surreal.Map
+	}{
+		{
+			builder.Select("article").
+				Fields("title", "author.name").
+				Where(builder.Eq("published", true), builder.Or(builder.Gt("views", 100), builder.Expr("featured ?? ? = ?", false, true))).
+				OrderByDesc("createdAt").
+				OrderBy("title").
+				Limit(10).
+				Start(20).
+				Fetch("author"),
+			"SELECT title, author.name FROM article WHERE (published = $p0) AND ((views > $p1) OR (featured ?? $p2 = $p3)) ORDER BY createdAt DESC, title LIMIT $p4 START $p5 FETCH author",
+			surreal.Map{"p0": true, "p1": 100, "p2": false, "p3": true, "p4": 10, "p5": 20},
+		},
+		{
+			builder.Select(surreal.NewRecordID("article", "'; DROP TABLE article; --")).Only().Value("title"),
+			"SELECT VALUE title FROM ONLY type::thing($p0, $p1)",
+			surreal.Map{"p0": "article", "p1": "'; DROP TABLE article; --"},
+		},
+		{
+			builder.Create("user profile").Set("name", "John").Set("tags", []string{"a"}).Return(builder.ReturnNone),
+			"CREATE ⟨user profile⟩ SET name = $p0, tags = $p1 RETURN NONE",
+			surreal.Map{"p0": "John", "p1": []string{"a"}},
+		},
+		{
+			builder.Update("article").Merge(surreal.Map{"draft": false}).Where(builder.Lt("createdAt", "2024-01-01")).Return(builder.ReturnDiff),
+			"UPDATE article MERGE $p0 WHERE createdAt < $p1 RETURN DIFF",
+			surreal.Map{"p0": surreal.Map{"draft": false}, "p1": "2024-01-01"},
+		},
+		{
+			builder.Upsert(surreal.NewRecordID("counter", 1)).Content(surreal.Map{"n": 1}),
+			"UPSERT type::thing($p0, $p1) CONTENT $p2",
+			surreal.Map{"p0": "counter", "p1": 1, "p2": surreal.Map{"n": 1}},
+		},
+		{
+			builder.Delete("session").Where(builder.Not(builder.Inside("user", []string{"user:1"}))),
+			"DELETE session WHERE !(user INSIDE $p0)",
+			surreal.Map{"p0": []string{"user:1"}},
+		},
+		{
+			builder.Select("venue").Where(
+				builder.Within("location", surreal.Polygon{{{0, 0}, {0, 1}, {1, 1}, {0, 0}}}),
+				builder.NearerThan("location", surreal.Point{0.5, 0.5}, 1000),
+			),
+			"SELECT * FROM venue WHERE (location INSIDE $p0) AND (geo::distance(location, $p1) < $p2)",
+			surreal.Map{"p0": surreal.Polygon{{{0, 0}, {0, 1}, {1, 1}, {0, 0}}}, "p1": surreal.Point{0.5, 0.5}, "p2": float64(1000)},
+		},
+		{
+			builder.Relate(surreal.NewRecordID("user", 1), "wrote", surreal.NewRecordID("article", 2)).Set("at", "now"),
+			"RELATE (type::thing($p0, $p1))->wrote->(type::thing($p2, $p3)) SET at = $p4",
+			surreal.Map{"p0": "user", "p1": 1, "p2": "article", "p3": 2, "p4": "now"},
+		},
+	}
+
+	for _, test := range tests {
+		query, vars, err := test.statement.Build()
+		if err != nil {
+			t.Errorf("unexpected Build error: %s", err)
+		}
+		if query != test.query {
+			t.Errorf("unexpected query:\n%s\nexpected:\n%s", query, test.query)
+		}
+		if !reflect.DeepEqual(vars, test.vars) {
+			t.Errorf("unexpected vars %v for %s, expected %v", vars, query, test.vars)
+		}
+	}
+}
+
+func TestExecute(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	server.Handle("query", func(params []any) (any, *rpc.Error) {
+		if params[0] != "SELECT * FROM article WHERE title = $p0" {
+			return nil, &rpc.Error{Code: -32000, Message: "unexpected query"}
+		}
+		return surrealtest.QueryResult([]surreal.Map{{"id": "article:1", "title": params[1].(map[string]any)["p0"]}}), nil
+	})
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	var articles []Article
+	if err := db.Execute(builder.Select("article").Where(builder.Eq("title", "Hello")), &articles); err != nil {
+		t.Fatalf("unexpected Execute error: %s", err)
+	}
+	if len(articles) != 1 || articles[0].Title != "Hello" {
+		t.Fatalf("unexpected result %+v", articles)
+	}
+}
+
+func TestBuilderMisuse(t *testing.T) {
+	tests := []struct {
+		statement surreal.Statement
+		message   string
+	}{
+		{builder.Select(), "has no targets"},
+		{builder.Delete(), "has no targets"},
+		{builder.Select(42), "unsupported target int"},
+		{builder.Select("article").Where(builder.Expr("views > ? AND likes > ?", 1)), "more placeholders"},
+		{builder.Update("article").Where(builder.Expr("views > ?", 1, 2)), "has 1 placeholders, got 2 arguments"},
+		{builder.Select("article").Where(builder.Or()), "OR of no conditions"},
+	}
+
+	for _, test := range tests {
+		query, vars, err := test.statement.Build()
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("expected an error about %q, got %v", test.message, err)
+		}
+		if query != "" || vars != nil {
+			t.Errorf("expected nothing to be built, got %s %v", query, vars)
+		}
+	}
+
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	if err := db.Execute(builder.Select()); err == nil || !strings.Contains(err.Error(), "has no targets") {
+		t.Fatalf("expected Execute to report the misuse, got %v", err)
+	}
+	for _, call := range server.Calls() {
+		if call.Method == "query" {
+			t.Fatalf("expected nothing to be sent, got %+v", call)
+		}
+	}
+}
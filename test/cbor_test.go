@@ -0,0 +1,141 @@
+package test
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gorilla/websocket"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type cborRecord struct {
+	ID       surreal.RecordID `json:"id"`
+	Created  surreal.Datetime `json:"created"`
+	TTL      surreal.Duration `json:"ttl"`
+	Price    surreal.Decimal  `json:"price"`
+	Token    surreal.UUID     `json:"token"`
+	Location surreal.Point    `json:"location"`
+	Missing  any              `json:"missing"`
+}
+
+func TestCBOREncoding(t *testing.T) {
+	var received []any
+	upgrader := websocket.Upgrader{Subprotocols: []string{"cbor"}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			messageType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType != websocket.BinaryMessage {
+				t.Errorf("expected a binary message, got %d", messageType)
+			}
+
+			var outgoing struct {
+				ID     string            `cbor:"id"`
+				Method string            `cbor:"method"`
+				Params []cbor.RawMessage `cbor:"params"`
+			}
+			if err := cbor.Unmarshal(msg, &outgoing); err != nil {
+				t.Errorf("failed to decode request: %s", err)
+				return
+			}
+
+			for _, param := range outgoing.Params {
+				var tag cbor.RawTag
+				if param[0]>>5 == 6 && cbor.Unmarshal(param, &tag) == nil {
+					received = append(received, tag.Number)
+				}
+			}
+
+			record := map[string]any{
+				"id":       cbor.Tag{Number: 8, Content: []any{"article", uint64(1)}},
+				"created":  cbor.Tag{Number: 12, Content: []int64{1700000000, 123456789}},
+				"ttl":      cbor.Tag{Number: 14, Content: []int64{90}},
+				"price":    cbor.Tag{Number: 10, Content: "12.3456789012345678901234567890"},
+				"token":    cbor.Tag{Number: 37, Content: []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}},
+				"location": cbor.Tag{Number: 88, Content: []float64{-0.118092, 51.509865}},
+				"missing":  cbor.Tag{Number: 6, Content: nil},
+			}
+
+			response, _ := cbor.Marshal(map[string]any{"id": outgoing.ID, "result": record})
+			if err := conn.WriteMessage(websocket.BinaryMessage, response); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	db, err := surreal.Connect("ws"+strings.TrimPrefix(server.URL, "http"), &surreal.Options{Encoding: surreal.EncodingCBOR})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	var record cborRecord
+	if err := db.Select("article:1", &record); err != nil {
+		t.Fatalf("unexpected Select error: %s", err)
+	}
+
+	if !record.ID.Equal(surreal.RecordID{Table: "article", ID: int64(1)}) {
+		t.Fatalf("unexpected record id: %+v", record.ID)
+	}
+	if !record.Created.Equal(time.Unix(1700000000, 123456789)) {
+		t.Fatalf("unexpected datetime: %s", record.Created)
+	}
+	if record.TTL != surreal.Duration(90*time.Second) {
+		t.Fatalf("unexpected duration: %d", record.TTL)
+	}
+	if record.Price != "12.3456789012345678901234567890" {
+		t.Fatalf("unexpected decimal: %s", record.Price)
+	}
+	if record.Token.String() != "01234567-89ab-cdef-0123-456789abcdef" {
+		t.Fatalf("unexpected uuid: %s", record.Token)
+	}
+	if record.Location != (surreal.Point{-0.118092, 51.509865}) {
+		t.Fatalf("unexpected point: %v", record.Location)
+	}
+	if record.Missing != surreal.None {
+		t.Fatalf("expected NONE, got: %#v", record.Missing)
+	}
+
+	var generic map[string]any
+	if err := db.Select("article:1", &generic); err != nil {
+		t.Fatalf("unexpected Select error: %s", err)
+	}
+	if _, ok := generic["id"].(surreal.RecordID); !ok {
+		t.Fatalf("expected record id to decode into RecordID, got: %#v", generic["id"])
+	}
+
+	received = nil
+	if err := db.Relate(surreal.RecordID{Table: "user", ID: 1}, "wrote", surreal.RecordID{Table: "article", ID: 1}, nil); err != nil {
+		t.Fatalf("unexpected Relate error: %s", err)
+	}
+	if len(received) != 2 || received[0] != uint64(8) || received[1] != uint64(8) {
+		t.Fatalf("expected record ids to be sent as tagged values, got tags: %v", received)
+	}
+}
+
+func TestCBORQueryStatus(t *testing.T) {
+	ok, _ := cbor.Marshal([]any{map[string]any{"status": "OK", "time": "1ms", "result": nil}})
+	var result rpc.RawResult
+	if err := cbor.Unmarshal(ok, &result); err != nil || len(result) != 1 || !result[0].OK {
+		t.Fatalf("expected an OK status, got %+v %v", result, err)
+	}
+
+	malformed, _ := cbor.Marshal([]any{map[string]any{"status": 1, "time": "1ms", "result": nil}})
+	if err := cbor.Unmarshal(malformed, &result); err == nil {
+		t.Fatalf("expected a malformed status to be rejected")
+	}
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// count returns the number of records logged with message.
+func (h *recordingHandler) count(message string) int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	n := 0
+	for _, record := range *h.records {
+		if record.Message == message {
+			n++
+		}
+	}
+	return n
+}
+
+func TestContextWebSocket(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	// version is only answered once released, long after the callers have given up
+	release := make(chan struct{})
+	server.Handle("version", func([]any) (any, *rpc.Error) {
+		<-release
+		return "late", nil
+	})
+
+	handler := newRecordingHandler()
+	db, err := surreal.Connect(server.URL, &surreal.Options{Logger: slog.New(handler)})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := db.VersionContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	release <- struct{}{}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := db.VersionContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	release <- struct{}{}
+
+	// calls are answered in order, so the late responses have arrived by the time this one is answered
+	server.Handle("version", nil)
+	if version, err := db.Version(); err != nil || version == "late" {
+		t.Fatalf("expected the late responses not to be taken for this one, got %q %v", version, err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatalf("unexpected Ping error: %s", err)
+	}
+
+	// a late response is only dropped as unexpected once nobody waits for it anymore
+	if n := handler.count("received response nobody waits for"); n != 2 {
+		t.Fatalf("expected both late responses to be dropped, got %d", n)
+	}
+}
+
+func TestContextHTTP(t *testing.T) {
+	release := make(chan struct{})
+	db := serveRPC(t, func(method string, params []any) (any, *rpc.Error) {
+		if method == "version" {
+			<-release
+			return "late", nil
+		}
+		return nil, nil
+	})
+	// the handlers still waiting must return for the server to shut down
+	t.Cleanup(func() { close(release) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := db.VersionContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := db.VersionContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		t.Fatalf("unexpected Ping error: %s", err)
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"errors"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"strings"
+	"testing"
+)
+
+func TestErrorSentinels(t *testing.T) {
+	db := serveRPC(t, func(method string, params []any) (any, *rpc.Error) {
+		switch method {
+		case "signin":
+			return nil, &rpc.Error{Code: -32000, Message: "There was a problem with authentication"}
+		case "delete":
+			return nil, &rpc.Error{Code: -32000, Message: "IAM error: Not enough permissions to perform this action"}
+		case "query":
+			return []surreal.Map{
+				{"status": "ERR", "time": "1ms", "result": "Database record `article:1` already exists"},
+			}, nil
+		}
+		return nil, nil
+	})
+
+	err := db.SignIn(surreal.AuthArgs{Namespace: "test", Database: "test"})
+	if !errors.Is(err, surreal.ErrAuth) {
+		t.Fatalf("expected ErrAuth, got %v", err)
+	}
+
+	err = db.Delete("article:1")
+	var rpcErr *rpc.Error
+	if !errors.Is(err, surreal.ErrPermission) || !errors.As(err, &rpcErr) || rpcErr.Code != -32000 {
+		t.Fatalf("expected ErrPermission wrapping the *rpc.Error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Not enough permissions") {
+		t.Fatalf("expected the server message to be kept, got %s", err)
+	}
+
+	err = db.Query("CREATE article:1", nil)
+	var queryErr surreal.QueryError
+	if !errors.Is(err, surreal.ErrAlreadyExists) || !errors.As(err, &queryErr) || queryErr.QueryNo != 0 {
+		t.Fatalf("expected ErrAlreadyExists wrapping a QueryError, got %v", err)
+	}
+
+	if _, err := surreal.SelectOne[Article](db, "article:1"); !errors.Is(err, surreal.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	_ = db.Close()
+	if err := db.Use("test", "test"); !errors.Is(err, surreal.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	messages := map[string]*rpc.Error{
+		"select": {Code: -32602, Message: "Method not allowed"},
+		"delete": {Code: -32603, Message: "Invalid params"},
+		"update": {Code: -32000, Message: "There was a problem with the database: The table 'article' does not exist"},
+		"create": {Code: -32000, Message: "There was a problem with the database: The record 'article:1' does not exist"},
+		"merge":  {Code: -32000, Message: "There was a problem with the database: Database index `title` already contains 'Hello', with record `article:2`"},
+		"signin": {Code: -32000, Message: "There was a problem with the database: No record was returned"},
+		"insert": {Code: -32000, Message: "There was a problem with the database: The query was not executed because it exceeded the timeout"},
+	}
+	db := serveRPC(t, func(method string, params []any) (any, *rpc.Error) {
+		return nil, messages[method]
+	})
+	defer db.Close()
+
+	var rpcErr *rpc.Error
+	if err := db.Select("article:1", nil); !errors.Is(err, surreal.ErrPermission) || !errors.As(err, &rpcErr) || rpcErr.Code != -32602 {
+		t.Fatalf("expected ErrPermission from the code, got %v", err)
+	}
+	if err := db.Delete("article:1"); errors.Is(err, surreal.ErrPermission) || !errors.As(err, &rpcErr) {
+		t.Fatalf("expected invalid params not to be classified, got %v", err)
+	}
+	if err := db.Update("article", nil); errors.Is(err, surreal.ErrNotFound) {
+		t.Fatalf("expected a missing table not to be ErrNotFound, got %v", err)
+	}
+	if err := db.Create("article:1", nil); !errors.Is(err, surreal.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := db.Merge("article:1", nil); !errors.Is(err, surreal.ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+	if err := db.SignIn(surreal.AuthArgs{Namespace: "test", Database: "test"}); errors.Is(err, surreal.ErrAuth) {
+		t.Fatalf("expected a missing record not to be ErrAuth, got %v", err)
+	}
+	if err := db.Insert("article", nil); !errors.Is(err, surreal.ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"testing"
+)
+
+func TestGenericHelpers(t *testing.T) {
+	articles := []surreal.Map{
+		{"id": "article:1", "title": "first"},
+		{"id": "article:2", "title": "second"},
+	}
+
+	db := serveRPC(t, func(method string, params []any) (any, *rpc.Error) {
+		switch method {
+		case "select":
+			if params[0] == "article" {
+				return articles, nil
+			}
+			for _, article := range articles {
+				if article["id"] == params[0] {
+					return article, nil
+				}
+			}
+			return nil, nil
+		case "create":
+			data := params[1].(map[string]any)
+			data["id"] = "article:3"
+			return []any{data}, nil
+		case "query":
+			return []surreal.Map{
+				{"status": "OK", "time": "1µs", "result": nil},
+				{"status": "OK", "time": "2µs", "result": articles},
+			}, nil
+		}
+		return nil, &rpc.Error{Code: -32601, Message: "Method not found"}
+	})
+
+	all, err := surreal.Select[Article](db, "article")
+	if err != nil {
+		t.Fatalf("unexpected Select error: %s", err)
+	}
+	if len(all) != 2 || all[1].Title != "second" {
+		t.Fatalf("unexpected articles: %+v", all)
+	}
+
+	one, err := surreal.SelectOne[Article](db, surreal.NewRecordID("article", 1))
+	if err != nil {
+		t.Fatalf("unexpected SelectOne error: %s", err)
+	}
+	if one.ID != "article:1" || one.Title != "first" {
+		t.Fatalf("unexpected article: %+v", one)
+	}
+
+	if _, err := surreal.SelectOne[Article](db, "article:404"); err == nil {
+		t.Fatalf("expected SelectOne of a missing record to fail")
+	}
+
+	created, err := surreal.Create[Article](db, "article", Article{Title: "third"})
+	if err != nil {
+		t.Fatalf("unexpected Create error: %s", err)
+	}
+	if created.ID != "article:3" || created.Title != "third" {
+		t.Fatalf("unexpected created article: %+v", created)
+	}
+
+	queried, err := surreal.QueryAs[Article](db, "LET $t = 'article'; SELECT * FROM type::table($t)", nil)
+	if err != nil {
+		t.Fatalf("unexpected QueryAs error: %s", err)
+	}
+	if len(queried) != 2 || queried[0].Title != "first" {
+		t.Fatalf("unexpected queried articles: %+v", queried)
+	}
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"reflect"
+	"testing"
+)
+
+type venue struct {
+	ID       surreal.RecordID           `json:"id"`
+	Location surreal.Point              `json:"location"`
+	Route    surreal.LineString         `json:"route"`
+	Area     surreal.Polygon            `json:"area"`
+	Zones    surreal.MultiPolygon       `json:"zones"`
+	Features surreal.GeometryCollection `json:"features"`
+}
+
+func TestGeometry(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	square := surreal.Polygon{{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}}
+	sent := venue{
+		ID:       surreal.NewRecordID("venue", "hall"),
+		Location: surreal.Point{-0.118092, 51.509865},
+		Route:    surreal.LineString{{0, 0}, {1, 1}},
+		Area:     square,
+		Zones:    surreal.MultiPolygon{square, square},
+		Features: surreal.GeometryCollection{surreal.Point{1, 2}, surreal.MultiPoint{{3, 4}}},
+	}
+	if err := db.Create("venue:hall", sent); err != nil {
+		t.Fatalf("unexpected Create error: %s", err)
+	}
+
+	stored, _ := server.Get("venue:hall")
+	location := map[string]any{"type": "Point", "coordinates": []any{-0.118092, 51.509865}}
+	if !reflect.DeepEqual(stored["location"], location) {
+		t.Fatalf("expected a GeoJSON point, got %v", stored["location"])
+	}
+	features := map[string]any{"type": "GeometryCollection", "geometries": []any{
+		map[string]any{"type": "Point", "coordinates": []any{1.0, 2.0}},
+		map[string]any{"type": "MultiPoint", "coordinates": []any{[]any{3.0, 4.0}}},
+	}}
+	if !reflect.DeepEqual(stored["features"], features) {
+		t.Fatalf("expected a GeoJSON geometry collection, got %v", stored["features"])
+	}
+
+	var selected venue
+	if err := db.Select("venue:hall", &selected); err != nil {
+		t.Fatalf("unexpected Select error: %s", err)
+	}
+	if !reflect.DeepEqual(selected, sent) {
+		t.Fatalf("unexpected venue %+v", selected)
+	}
+
+	server.Handle("query", func(params []any) (any, *rpc.Error) {
+		return surrealtest.QueryResult(stored["location"], []any{stored["route"]}), nil
+	})
+	var point surreal.Point
+	var routes []surreal.LineString
+	if err := db.Query("RETURN $location; SELECT VALUE route FROM venue", nil, &point, &routes); err != nil {
+		t.Fatalf("unexpected Query error: %s", err)
+	}
+	if point != sent.Location || !reflect.DeepEqual(routes, []surreal.LineString{sent.Route}) {
+		t.Fatalf("unexpected geometries %v, %v", point, routes)
+	}
+
+	server.Handle("select", func(params []any) (any, *rpc.Error) {
+		return stored["area"], nil
+	})
+	var area surreal.Polygon
+	if err := db.Select("venue:hall", &area); err != nil {
+		t.Fatalf("unexpected Select error: %s", err)
+	}
+	if !reflect.DeepEqual(area, square) {
+		t.Fatalf("expected a single polygon to be scanned, got %v", area)
+	}
+
+	raw, _ := json.Marshal(stored["zones"])
+	zones, err := surreal.ParseGeoJSON(raw)
+	if err != nil || !reflect.DeepEqual(zones, sent.Zones) {
+		t.Fatalf("unexpected parsed geometry %#v, %v", zones, err)
+	}
+	if err := json.Unmarshal(raw, &routes); err == nil {
+		t.Fatalf("expected a multipolygon not to decode into a line")
+	}
+}
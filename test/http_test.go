@@ -0,0 +1,111 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPConnection(t *testing.T) {
+	var headers http.Header
+	var calls []rpc.Outgoing
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rpc" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var outgoing rpc.Outgoing
+		if err := json.NewDecoder(r.Body).Decode(&outgoing); err != nil {
+			t.Errorf("failed to decode request: %s", err)
+			return
+		}
+		headers = r.Header
+		calls = append(calls, outgoing)
+
+		var result any
+		switch outgoing.Method {
+		case "signin":
+			result = "token"
+		case "query":
+			result = []surreal.Map{{"status": "OK", "time": "1ms", "result": outgoing.Params[1]}}
+		}
+
+		_ = json.NewEncoder(w).Encode(surreal.Map{"id": outgoing.ID, "result": result})
+	}))
+	defer server.Close()
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	if err := db.Use("test", "test"); err != nil {
+		t.Fatalf("unexpected Use error: %s", err)
+	}
+
+	if err := db.SignIn(surreal.AuthArgs{Namespace: "test", Database: "test", Other: surreal.Map{"user": "test", "pass": "test"}}); err != nil {
+		t.Fatalf("unexpected SignIn error: %s", err)
+	}
+
+	if err := db.Let("bound", 1); err != nil {
+		t.Fatalf("unexpected Let error: %s", err)
+	}
+
+	var vars map[string]int
+	if err := db.Query("RETURN $bound + $own", surreal.Map{"own": 2}, &vars); err != nil {
+		t.Fatalf("unexpected Query error: %s", err)
+	}
+
+	if vars["bound"] != 1 || vars["own"] != 2 {
+		t.Fatalf("session variables were not sent along: %+v", vars)
+	}
+
+	if headers.Get("Surreal-NS") != "test" || headers.Get("Surreal-DB") != "test" || headers.Get("Authorization") != "Bearer token" {
+		t.Fatalf("session headers were not sent along: %+v", headers)
+	}
+
+	if len(calls) != 2 || calls[0].Method != "signin" || calls[1].Method != "query" {
+		t.Fatalf("unexpected calls: %+v", calls)
+	}
+
+	var unsupported *surreal.UnsupportedMethodError
+	if _, err := db.Live("article", func(rpc.LiveNotification) {}, false); !errors.As(err, &unsupported) {
+		t.Fatalf("expected UnsupportedMethodError, got: %v", err)
+	}
+}
+
+// serveRPC starts an HTTP /rpc endpoint answering calls with handler, and connects to it.
+func serveRPC(t *testing.T, handler func(method string, params []any) (any, *rpc.Error)) *surreal.DB {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var outgoing rpc.Outgoing
+		if err := json.NewDecoder(r.Body).Decode(&outgoing); err != nil {
+			t.Errorf("failed to decode request: %s", err)
+			return
+		}
+
+		result, err := handler(outgoing.Method, outgoing.Params)
+		if err != nil {
+			_ = json.NewEncoder(w).Encode(surreal.Map{"id": outgoing.ID, "error": err})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(surreal.Map{"id": outgoing.ID, "result": result})
+	}))
+	t.Cleanup(server.Close)
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
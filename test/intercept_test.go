@@ -0,0 +1,83 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	if err := server.Put("article:real", surreal.Map{"title": "real"}); err != nil {
+		t.Fatalf("unexpected Put error: %s", err)
+	}
+
+	var order []string
+	cached := 0
+	db, err := surreal.Connect(server.URL, &surreal.Options{
+		Interceptors: []surreal.Interceptor{
+			func(ctx context.Context, method string, params []any, next surreal.Invoker) ([]byte, error) {
+				order = append(order, "outer "+method)
+				return next(ctx, method, params)
+			},
+			// retries once on permission errors
+			func(ctx context.Context, method string, params []any, next surreal.Invoker) ([]byte, error) {
+				result, err := next(ctx, method, params)
+				if errors.Is(err, surreal.ErrPermission) {
+					order = append(order, "retry "+method)
+					return next(ctx, method, params)
+				}
+				return result, err
+			},
+			// answers versions locally and redirects selects of article:alias
+			func(ctx context.Context, method string, params []any, next surreal.Invoker) ([]byte, error) {
+				switch {
+				case method == "version":
+					cached++
+					return []byte(`"surrealdb-cached"`), nil
+				case method == "select" && params[0] == "article:alias":
+					params = []any{"article:real"}
+				}
+				return next(ctx, method, params)
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	if version, err := db.Version(); err != nil || version != "surrealdb-cached" {
+		t.Fatalf("unexpected Version result %s %v", version, err)
+	}
+	if cached != 1 {
+		t.Fatalf("expected the version to be answered by the interceptor")
+	}
+
+	server.Fail("select", &rpc.Error{Code: -32000, Message: "IAM error: Not enough permissions"})
+	article, err := surreal.SelectOne[Article](db, "article:alias")
+	if err != nil || article.Title != "real" {
+		t.Fatalf("unexpected select result %+v %v", article, err)
+	}
+
+	expected := []string{"outer version", "outer select", "retry select"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, order)
+		}
+	}
+
+	for _, call := range server.Calls() {
+		if call.Method == "version" {
+			t.Fatalf("expected version not to reach the server")
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"context"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingHandler keeps the records logged through it.
+type recordingHandler struct {
+	lock    *sync.Mutex
+	records *[]slog.Record
+	attrs   []slog.Attr
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{lock: &sync.Mutex{}, records: &[]slog.Record{}}
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, record slog.Record) error {
+	record = record.Clone()
+	record.AddAttrs(h.attrs...)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	*h.records = append(*h.records, record)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &recordingHandler{lock: h.lock, records: h.records, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+// find returns the attributes of the first record logged with message, or nil.
+func (h *recordingHandler) find(message string) map[string]slog.Value {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for _, record := range *h.records {
+		if record.Message == message {
+			attrs := map[string]slog.Value{"level": slog.StringValue(record.Level.String())}
+			record.Attrs(func(attr slog.Attr) bool {
+				attrs[attr.Key] = attr.Value
+				return true
+			})
+			return attrs
+		}
+	}
+	return nil
+}
+
+func (h *recordingHandler) await(t *testing.T, message string) map[string]slog.Value {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if attrs := h.find(message); attrs != nil {
+			return attrs
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %q to be logged", message)
+	return nil
+}
+
+func TestLogger(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	handler := newRecordingHandler()
+	db, err := surreal.Connect(server.URL, &surreal.Options{
+		Logger:            slog.New(handler),
+		SlowCallThreshold: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	if attrs := handler.await(t, "connected to websocket"); attrs["url"].String() != server.URL {
+		t.Fatalf("expected the url to be attached, got %v", attrs)
+	}
+
+	server.Handle("version", func(params []any) (any, *rpc.Error) {
+		time.Sleep(30 * time.Millisecond)
+		return surrealtest.Version, nil
+	})
+	if _, err := db.Version(); err != nil {
+		t.Fatalf("unexpected Version error: %s", err)
+	}
+	slow := handler.await(t, "slow call")
+	if slow["method"].String() != "version" || slow["id"].String() == "" || slow["level"].String() != "WARN" {
+		t.Fatalf("unexpected slow call record %v", slow)
+	}
+
+	sub, err := db.Subscribe("article", &surreal.SubscriptionOptions{BufferSize: 1, Overflow: surreal.OverflowDropNewest})
+	if err != nil {
+		t.Fatalf("unexpected Subscribe error: %s", err)
+	}
+	defer sub.Close()
+	for _, id := range []string{"article:1", "article:2"} {
+		if err := db.Create(id, Article{Title: id}); err != nil {
+			t.Fatalf("unexpected Create error: %s", err)
+		}
+	}
+	if dropped := handler.await(t, "subscription dropped live notification"); dropped["live_id"].String() != sub.ID() {
+		t.Fatalf("unexpected dropped notification record %v", dropped)
+	}
+
+	server.DropConnections()
+	if dropped := handler.await(t, "websocket connection dropped"); dropped["reason"].String() == "" {
+		t.Fatalf("expected the reason to be attached, got %v", dropped)
+	}
+}
@@ -0,0 +1,25 @@
+package test
+
+import (
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"os"
+	"testing"
+)
+
+// surrealURL is the server the tests against a database run on. Unless SURREAL_URL points them at a real SurrealDB,
+// e.g. ws://localhost:8000/rpc, they run against an in-process surrealtest server.
+var surrealURL string
+
+func TestMain(m *testing.M) {
+	surrealURL = os.Getenv("SURREAL_URL")
+	if surrealURL == "" {
+		server := surrealtest.NewServer()
+		surrealURL = server.URL
+
+		code := m.Run()
+		server.Close()
+		os.Exit(code)
+	}
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,206 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/migrate"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// migrationServer scripts the queries the migrate package sends, keeping applied migrations and the lock in memory.
+type migrationServer struct {
+	lock         sync.Mutex
+	holder       string
+	applied      map[string]map[string]any
+	transactions []string
+}
+
+func (s *migrationServer) handle(params []any) (any, *rpc.Error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	query := params[0].(string)
+	vars, _ := params[1].(map[string]any)
+
+	switch {
+	case strings.HasPrefix(query, "CREATE type::thing($lock"):
+		if s.holder != "" {
+			return []map[string]any{surrealtest.QueryError("Database record `_migrations_lock:lock` already exists")}, nil
+		}
+		s.holder = vars["holder"].(string)
+	case strings.HasPrefix(query, "DELETE type::thing($lock"):
+		if !strings.Contains(query, "WHERE") || s.holder == vars["holder"] {
+			s.holder = ""
+		}
+	case strings.HasPrefix(query, "SELECT version"):
+		records := []map[string]any{}
+		for _, record := range s.applied {
+			records = append(records, record)
+		}
+		return surrealtest.QueryResult(records), nil
+	case strings.HasPrefix(query, "BEGIN TRANSACTION"):
+		if strings.Contains(query, "THROW") {
+			return []map[string]any{surrealtest.QueryError("An error occurred: broken")}, nil
+		}
+		s.transactions = append(s.transactions, query)
+		version := vars["version"].(string)
+		if strings.Contains(query, "CREATE type::thing($table, $version)") {
+			s.applied[version] = map[string]any{
+				"version":    version,
+				"name":       vars["name"],
+				"checksum":   vars["checksum"],
+				"applied_at": "2024-01-02T03:04:05Z",
+			}
+		} else {
+			delete(s.applied, version)
+		}
+	default:
+		return nil, &rpc.Error{Code: -32000, Message: "unexpected query " + query}
+	}
+
+	return surrealtest.QueryResult(nil, nil, nil, nil, nil, nil, nil, nil), nil
+}
+
+func TestMigrate(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	fake := &migrationServer{applied: map[string]map[string]any{}}
+	server.Handle("query", fake.handle)
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	files := fstest.MapFS{
+		"0001_users.up.surql":   {Data: []byte("DEFINE TABLE user SCHEMAFULL;\nDEFINE FIELD name ON user TYPE string;\n")},
+		"0001_users.down.surql": {Data: []byte("REMOVE TABLE user;")},
+		"0002_posts.surql":      {Data: []byte("DEFINE TABLE post SCHEMALESS;")},
+		"10_tags.up.surql":      {Data: []byte("DEFINE TABLE tag;")},
+		"10_tags.down.surql":    {Data: []byte("REMOVE TABLE tag;")},
+		"README.md":             {Data: []byte("not a migration")},
+	}
+
+	migrator, err := migrate.New(db, files, nil)
+	if err != nil {
+		t.Fatalf("unexpected New error: %s", err)
+	}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("unexpected Up error: %s", err)
+	}
+	if len(applied) != 3 || applied[0].Version != "0001" || applied[1].Version != "0002" || applied[2].Name != "tags" {
+		t.Fatalf("unexpected applied migrations %+v", applied)
+	}
+	if !strings.Contains(fake.transactions[0], "DEFINE FIELD name ON user TYPE string;\nCREATE type::thing($table, $version)") {
+		t.Fatalf("expected the migration to be recorded in its transaction, got %s", fake.transactions[0])
+	}
+	if fake.holder != "" {
+		t.Fatalf("expected the lock to be released")
+	}
+
+	if applied, err := migrator.Up(); err != nil || len(applied) != 0 {
+		t.Fatalf("expected nothing to be applied, got %+v, %v", applied, err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("unexpected Status error: %s", err)
+	}
+	for _, status := range statuses {
+		if !status.Applied || status.AppliedAt.IsZero() {
+			t.Fatalf("unexpected status %+v", status)
+		}
+	}
+
+	fake.holder = "elsewhere:1"
+	if _, err := migrator.Up(); !errors.Is(err, migrate.ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if err := migrator.Unlock(); err != nil || fake.holder != "" {
+		t.Fatalf("expected the lock to be released, got %v", err)
+	}
+
+	if _, err := migrator.Down(2); err == nil || len(fake.applied) != 3 {
+		t.Fatalf("expected reverting a migration without a down script to fail, got %v", err)
+	}
+	reverted, err := migrator.Down(1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != "10" || len(fake.applied) != 2 {
+		t.Fatalf("unexpected reverted migrations %+v, %v", reverted, err)
+	}
+
+	var out bytes.Buffer
+	dryRun, _ := migrate.New(db, files, &migrate.Options{DryRun: &out})
+	transactions := len(fake.transactions)
+	if applied, err := dryRun.Up(); err != nil || len(applied) != 1 {
+		t.Fatalf("unexpected dry run %+v, %v", applied, err)
+	}
+	if len(fake.transactions) != transactions || !strings.Contains(out.String(), "-- 10_tags\nBEGIN TRANSACTION;\nDEFINE TABLE tag;\nCREATE type::thing(\"_migrations\", \"10\")") {
+		t.Fatalf("unexpected dry run output:\n%s", out.String())
+	}
+
+	files["0001_users.up.surql"] = &fstest.MapFile{Data: []byte("DEFINE TABLE user SCHEMALESS;")}
+	edited, _ := migrate.New(db, files, nil)
+	if _, err := edited.Up(); !errors.Is(err, migrate.ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+	if fake.holder != "" {
+		t.Fatalf("expected the lock to be released after a failure")
+	}
+
+	delete(files, "0001_users.up.surql")
+	delete(files, "0001_users.down.surql")
+	files["10_tags.up.surql"] = &fstest.MapFile{Data: []byte("THROW 'broken';")}
+	missing, _ := migrate.New(db, files, nil)
+	if _, err := missing.Status(); !errors.Is(err, migrate.ErrUnknownMigration) {
+		t.Fatalf("expected ErrUnknownMigration, got %v", err)
+	}
+
+	fake.applied = map[string]map[string]any{}
+	if applied, err := missing.Up(); err == nil || len(applied) != 1 || len(fake.applied) != 1 {
+		t.Fatalf("expected the failing migration to stop the run, got %+v, %v", applied, err)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := migrate.Load(fstest.MapFS{
+		"10_tags.surql":          {Data: []byte("DEFINE TABLE tag;")},
+		"0002_posts.up.surql":    {Data: []byte("DEFINE TABLE post;")},
+		"0002_posts.down.surql":  {Data: []byte("REMOVE TABLE post;")},
+		"0001_users.up.surql":    {Data: []byte("DEFINE TABLE user;")},
+		"0001_users.down.surql":  {Data: []byte("")},
+		"README.md":              {Data: []byte("ignored")},
+		"0003_ignored.surql.bak": {Data: []byte("ignored")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected Load error: %s", err)
+	}
+	if len(migrations) != 3 || migrations[0].Version != "0001" || migrations[1].Version != "0002" || migrations[2].Version != "10" {
+		t.Fatalf("unexpected migrations %+v", migrations)
+	}
+
+	invalid := []struct {
+		files   fstest.MapFS
+		message string
+	}{
+		{fstest.MapFS{"1_a.surql": {Data: []byte("DEFINE TABLE a;")}, "01_b.surql": {Data: []byte("DEFINE TABLE b;")}}, "share a version"},
+		{fstest.MapFS{"1_a.up.surql": {Data: []byte("DEFINE TABLE a;")}, "01_a.down.surql": {Data: []byte("REMOVE TABLE a;")}}, "share a version"},
+		{fstest.MapFS{"1_a.up.surql": {Data: []byte(" \n")}}, "has an empty up script"},
+		{fstest.MapFS{"1_a.down.surql": {Data: []byte("REMOVE TABLE a;")}}, "has no up script"},
+		{fstest.MapFS{"1_a.up.surql": {Data: []byte("")}, "1_a.surql": {Data: []byte("DEFINE TABLE a;")}}, "more than one up script"},
+	}
+	for _, test := range invalid {
+		if _, err := migrate.Load(test.files); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("expected an error about %q, got %v", test.message, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	pool, err := surreal.ConnectPool(server.URL, &surreal.Options{
+		PoolOptions: surreal.PoolOptions{Size: 3, HealthCheckInterval: 10 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer pool.Close()
+
+	if err := pool.Use("test", "test"); err != nil {
+		t.Fatalf("unexpected Use error: %s", err)
+	}
+	if err := pool.SignIn(surreal.AuthArgs{Namespace: "test", Database: "test", Other: surreal.Map{"user": "test", "pass": "test"}}); err != nil {
+		t.Fatalf("unexpected SignIn error: %s", err)
+	}
+
+	counts := map[string]int{}
+	for _, call := range server.Calls() {
+		counts[call.Method]++
+		if call.Method == "authenticate" && call.Params[0] != surrealtest.Token {
+			t.Fatalf("unexpected token %v", call.Params[0])
+		}
+	}
+	if counts["use"] != 3 || counts["signin"] != 1 || counts["authenticate"] != 2 {
+		t.Fatalf("expected the session to be set up on every connection, got %v", counts)
+	}
+
+	// the server answers the calls of a connection one at a time, so three calls only run at once when each is
+	// sent over a different connection
+	var running atomic.Int32
+	release := make(chan struct{})
+	server.Handle("query", func(params []any) (any, *rpc.Error) {
+		running.Add(1)
+		<-release
+		return surrealtest.QueryResult(nil), nil
+	})
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pool.Query("RETURN NONE", nil); err != nil {
+				t.Errorf("unexpected Query error: %s", err)
+			}
+		}()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for running.Load() != 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if running.Load() != 3 {
+		t.Fatalf("expected the calls to be spread over all connections, %d ran at once", running.Load())
+	}
+
+	events, err := surreal.SubscribeAs[Article](pool.DB, "article", nil)
+	if err != nil {
+		t.Fatalf("unexpected SubscribeAs error: %s", err)
+	}
+	if err := pool.Create("article:pooled", Article{Title: "pooled"}); err != nil {
+		t.Fatalf("unexpected Create error: %s", err)
+	}
+	select {
+	case event := <-events.Events():
+		if event.Record.Title != "pooled" {
+			t.Fatalf("unexpected event %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for live event")
+	}
+	if err := events.Close(); err != nil {
+		t.Fatalf("unexpected Close error: %s", err)
+	}
+
+	server.Handle("ping", func(params []any) (any, *rpc.Error) {
+		return nil, &rpc.Error{Code: -32000, Message: "unavailable"}
+	})
+	deadline = time.Now().Add(5 * time.Second)
+	for pool.Healthy() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if pool.Healthy() != 0 {
+		t.Fatalf("expected failed health checks to mark connections unhealthy")
+	}
+
+	server.Handle("ping", nil)
+	deadline = time.Now().Add(5 * time.Second)
+	for pool.Healthy() != pool.Size() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if pool.Healthy() != pool.Size() {
+		t.Fatalf("expected connections to recover, %d of %d healthy", pool.Healthy(), pool.Size())
+	}
+}
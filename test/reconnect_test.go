@@ -0,0 +1,328 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reconnectServer is a websocket server recording the calls made on each connection. Live queries are handed out ids
+// naming the connection they were started on, and a push on the latest connection notifies the live query last
+// started on it.
+type reconnectServer struct {
+	*httptest.Server
+
+	lock   sync.Mutex
+	calls  [][]rpc.Outgoing
+	conns  []*websocket.Conn
+	lives  []string
+	refuse bool
+	dials  int
+	// dropOn closes the connection with the given index, rather than answering, once it is called with the method
+	dropOn map[int]string
+}
+
+func newReconnectServer(t *testing.T) *reconnectServer {
+	s := &reconnectServer{}
+	upgrader := websocket.Upgrader{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		s.dials++
+		if s.refuse {
+			s.lock.Unlock()
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		s.lock.Unlock()
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		s.lock.Lock()
+		index := len(s.conns)
+		s.conns = append(s.conns, conn)
+		s.calls = append(s.calls, nil)
+		s.lock.Unlock()
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var outgoing rpc.Outgoing
+			if err := json.Unmarshal(msg, &outgoing); err != nil {
+				t.Errorf("failed to decode request: %s", err)
+				return
+			}
+
+			var result any
+			s.lock.Lock()
+			s.calls[index] = append(s.calls[index], outgoing)
+			if s.dropOn[index] == outgoing.Method {
+				s.lock.Unlock()
+				return
+			}
+			if outgoing.Method == "live" {
+				result = "live-" + strconv.Itoa(index)
+				s.lives = append(s.lives, result.(string))
+			}
+			_ = conn.WriteJSON(surreal.Map{"id": outgoing.ID, "result": result})
+			s.lock.Unlock()
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// drop closes the latest connection from the server side.
+func (s *reconnectServer) drop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_ = s.conns[len(s.conns)-1].Close()
+}
+
+// push sends a notification for the live query last started on the latest connection.
+func (s *reconnectServer) push(n int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_ = s.conns[len(s.conns)-1].WriteJSON(surreal.Map{"result": surreal.Map{
+		"id": s.lives[len(s.lives)-1], "action": "CREATE", "result": surreal.Map{"id": "article:" + strconv.Itoa(n), "n": n},
+	}})
+}
+
+// order returns the methods called on the connection with the given index, in order.
+func (s *reconnectServer) order(index int) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var methods []string
+	for _, call := range s.calls[index] {
+		methods = append(methods, call.Method)
+	}
+	return methods
+}
+
+// methods returns the methods called on the connection with the given index, along with their params.
+func (s *reconnectServer) methods(index int) map[string][]any {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	methods := make(map[string][]any)
+	for _, call := range s.calls[index] {
+		methods[call.Method] = append(methods[call.Method], call.Params)
+	}
+	return methods
+}
+
+func TestReconnectRestoresSession(t *testing.T) {
+	server := newReconnectServer(t)
+
+	reconnected := make(chan error, 1)
+	db, err := surreal.Connect("ws"+strings.TrimPrefix(server.URL, "http"), &surreal.Options{
+		WebSocketOptions: surreal.WebSocketOptions{
+			Reconnect:           true,
+			ReconnectBackoff:    time.Millisecond,
+			OnReconnectCallback: func(err error) { reconnected <- err },
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	if err := db.Use("test", "test"); err != nil {
+		t.Fatalf("unexpected Use error: %s", err)
+	}
+	if err := db.Authenticate("token"); err != nil {
+		t.Fatalf("unexpected Authenticate error: %s", err)
+	}
+	if err := db.Let("answer", 42); err != nil {
+		t.Fatalf("unexpected Let error: %s", err)
+	}
+
+	notifications := make(chan rpc.LiveNotification, 1)
+	id, err := db.Live("article", func(notification rpc.LiveNotification) { notifications <- notification }, false)
+	if err != nil {
+		t.Fatalf("unexpected Live error: %s", err)
+	}
+	if id != "live-0" {
+		t.Fatalf("expected live-0, got %s", id)
+	}
+
+	server.drop()
+	select {
+	case err := <-reconnected:
+		if err != nil {
+			t.Fatalf("unexpected reconnect error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnect")
+	}
+
+	replayed := server.methods(1)
+	if params := replayed["use"]; len(params) != 1 || params[0].([]any)[0] != "test" || params[0].([]any)[1] != "test" {
+		t.Fatalf("expected use to be replayed, got %v", params)
+	}
+	if params := replayed["authenticate"]; len(params) != 1 || params[0].([]any)[0] != "token" {
+		t.Fatalf("expected authenticate to be replayed, got %v", params)
+	}
+	if params := replayed["let"]; len(params) != 1 || params[0].([]any)[0] != "answer" || params[0].([]any)[1] != float64(42) {
+		t.Fatalf("expected let to be replayed, got %v", params)
+	}
+	if params := replayed["live"]; len(params) != 1 || params[0].([]any)[0] != "article" {
+		t.Fatalf("expected live to be replayed, got %v", params)
+	}
+
+	// the live query now runs as live-1 on the server, but keeps its id and callback
+	server.push(1)
+	select {
+	case notification := <-notifications:
+		if notification.ID != "live-0" {
+			t.Fatalf("expected the notification to be routed to live-0, got %s", notification.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the notification")
+	}
+
+	if err := db.Kill(id); err != nil {
+		t.Fatalf("unexpected Kill error: %s", err)
+	}
+	if params := server.methods(1)["kill"]; len(params) != 1 || params[0].([]any)[0] != "live-1" {
+		t.Fatalf("expected the server id to be killed, got %v", params)
+	}
+}
+
+func TestReconnectGivesUp(t *testing.T) {
+	server := newReconnectServer(t)
+
+	reconnected := make(chan error, 1)
+	db, err := surreal.Connect("ws"+strings.TrimPrefix(server.URL, "http"), &surreal.Options{
+		WebSocketOptions: surreal.WebSocketOptions{
+			Reconnect:            true,
+			ReconnectMaxAttempts: 3,
+			ReconnectBackoff:     time.Millisecond,
+			OnReconnectCallback:  func(err error) { reconnected <- err },
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	server.lock.Lock()
+	server.refuse = true
+	server.lock.Unlock()
+	server.drop()
+
+	select {
+	case err := <-reconnected:
+		if err == nil {
+			t.Fatal("expected the last dial error once reconnecting is given up")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnecting to be given up")
+	}
+
+	server.lock.Lock()
+	dials := server.dials
+	server.lock.Unlock()
+	if dials != 4 {
+		t.Fatalf("expected the initial dial and 3 attempts, got %d dials", dials)
+	}
+
+	if err := db.Ping(); !errors.Is(err, surreal.ErrClosed) {
+		t.Fatalf("expected ErrClosed once reconnecting is given up, got %v", err)
+	}
+}
+
+func TestReconnectDroppedDuringRestore(t *testing.T) {
+	server := newReconnectServer(t)
+	server.dropOn = map[int]string{1: "use"}
+
+	dropped := make(chan error, 2)
+	reconnected := make(chan error, 2)
+	db, err := surreal.Connect("ws"+strings.TrimPrefix(server.URL, "http"), &surreal.Options{
+		WebSocketOptions: surreal.WebSocketOptions{
+			Reconnect:           true,
+			ReconnectBackoff:    time.Millisecond,
+			OnDropCallback:      func(reason error) { dropped <- reason },
+			OnReconnectCallback: func(err error) { reconnected <- err },
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	if err := db.Use("test", "test"); err != nil {
+		t.Fatalf("unexpected Use error: %s", err)
+	}
+	if err := db.Let("answer", 42); err != nil {
+		t.Fatalf("unexpected Let error: %s", err)
+	}
+
+	server.drop()
+	select {
+	case <-dropped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the drop")
+	}
+
+	// held back until a session has been restored, which the first reconnect never manages
+	pinged := make(chan error, 1)
+	go func() { pinged <- db.Ping() }()
+
+	select {
+	case err := <-reconnected:
+		if err == nil {
+			t.Fatal("expected the restore interrupted by the drop to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the interrupted restore")
+	}
+	select {
+	case err := <-reconnected:
+		if err != nil {
+			t.Fatalf("unexpected reconnect error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the second restore")
+	}
+
+	select {
+	case err := <-pinged:
+		if err != nil {
+			t.Fatalf("unexpected Ping error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the held back Ping")
+	}
+
+	if methods := server.order(1); len(methods) != 1 || methods[0] != "use" {
+		t.Fatalf("expected nothing but the interrupted replay on the first reconnect, got %v", methods)
+	}
+	if methods := server.order(2); len(methods) != 3 || methods[0] != "use" || methods[1] != "let" || methods[2] != "ping" {
+		t.Fatalf("expected the Ping to follow the second replay, got %v", methods)
+	}
+
+	if err := db.Ping(); err != nil {
+		t.Fatalf("unexpected Ping error after the second restore: %s", err)
+	}
+}
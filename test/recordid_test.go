@@ -0,0 +1,99 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/terawatthour/surreal-go"
+	"reflect"
+	"testing"
+)
+
+func TestParseRecordID(t *testing.T) {
+	cases := []struct {
+		input     string
+		expected  surreal.RecordID
+		formatted string
+	}{
+		{"users:eqxomgmyq9z4lnl1gp65", surreal.NewRecordID("users", "eqxomgmyq9z4lnl1gp65"), ""},
+		{"article:1", surreal.NewRecordID("article", int64(1)), ""},
+		{"article:-1", surreal.NewRecordID("article", int64(-1)), ""},
+		{"article:⟨1⟩", surreal.NewRecordID("article", "1"), ""},
+		{"article:⟨hello world⟩", surreal.NewRecordID("article", "hello world"), ""},
+		{"article:`hello world`", surreal.NewRecordID("article", "hello world"), "article:⟨hello world⟩"},
+		{`article:⟨with \⟩ bracket⟩`, surreal.NewRecordID("article", "with ⟩ bracket"), ""},
+		{"⟨some table⟩:x", surreal.NewRecordID("some table", "x"), ""},
+		{"temperature:['London', 2024]", surreal.NewRecordID("temperature", []any{"London", int64(2024)}), ""},
+		{"person:{ age: 30, name: 'John' }", surreal.NewRecordID("person", map[string]any{"name": "John", "age": int64(30)}), ""},
+		{"wrote:[user:1, article:⟨a b⟩]", surreal.NewRecordID("wrote", []any{surreal.NewRecordID("user", int64(1)), surreal.NewRecordID("article", "a b")}), ""},
+		{"article:1..=5", surreal.NewRecordID("article", surreal.Range{
+			Begin: &surreal.Bound{Value: int64(1), Inclusive: true},
+			End:   &surreal.Bound{Value: int64(5), Inclusive: true},
+		}), ""},
+		{"article:..5", surreal.NewRecordID("article", surreal.Range{
+			End: &surreal.Bound{Value: int64(5)},
+		}), ""},
+	}
+
+	for _, c := range cases {
+		parsed, err := surreal.ParseRecordID(c.input)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %s", c.input, err)
+		}
+		if !reflect.DeepEqual(parsed, c.expected) {
+			t.Fatalf("parsed %s into %#v, expected %#v", c.input, parsed, c.expected)
+		}
+
+		formatted := c.formatted
+		if formatted == "" {
+			formatted = c.input
+		}
+		if parsed.String() != formatted {
+			t.Fatalf("formatted %s as %s, expected %s", c.input, parsed.String(), formatted)
+		}
+	}
+
+	for _, invalid := range []string{"", "article", "article:", ":1", "article:⟨unterminated", "article:1 trailing"} {
+		if _, err := surreal.ParseRecordID(invalid); err == nil {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestRecordIDJSON(t *testing.T) {
+	type record struct {
+		ID surreal.RecordID `json:"id"`
+	}
+
+	var decoded record
+	if err := json.Unmarshal([]byte(`{"id": "article:⟨hello world⟩"}`), &decoded); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !decoded.ID.Equal(surreal.NewRecordID("article", "hello world")) {
+		t.Fatalf("unexpected record id: %#v", decoded.ID)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(encoded) != `{"id":"article:⟨hello world⟩"}` {
+		t.Fatalf("unexpected encoding: %s", encoded)
+	}
+}
+
+func TestRecordIDEqual(t *testing.T) {
+	object := surreal.NewRecordID("person", map[string]any{"name": "John", "age": int64(30)})
+	if !object.Equal(surreal.MustParseRecordID("person:{ name: 'John', age: 30 }")) {
+		t.Fatalf("expected object ids to be equal")
+	}
+	if object.Equal(surreal.MustParseRecordID("person:{ name: 'Jane', age: 30 }")) {
+		t.Fatalf("expected object ids with different fields to differ")
+	}
+
+	array := surreal.NewRecordID("temperature", []any{"London", int64(2024)})
+	if !array.Equal(surreal.NewRecordID("temperature", []any{"London", float64(2024)})) {
+		t.Fatalf("expected array ids to be equal whatever type their numbers were decoded into")
+	}
+	if array.Equal(surreal.NewRecordID("weather", []any{"London", int64(2024)})) {
+		t.Fatalf("expected ids in different tables to differ")
+	}
+}
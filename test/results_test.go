@@ -0,0 +1,65 @@
+package test
+
+import (
+	"errors"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"testing"
+	"time"
+)
+
+func TestQueryResults(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	server.Handle("query", func(params []any) (any, *rpc.Error) {
+		return []surreal.Map{
+			{"status": "OK", "time": "1.5ms", "result": []surreal.Map{{"id": "article:1", "title": "first"}}},
+			surrealtest.QueryError("Database record `article:1` already exists"),
+			{"status": "OK", "time": "250µs", "result": 3},
+		}, nil
+	})
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	results, err := db.QueryResults("SELECT * FROM article; CREATE article:1; RETURN 3", nil)
+	if err != nil {
+		t.Fatalf("unexpected QueryResults error: %s", err)
+	}
+
+	if results.Len() != 3 {
+		t.Fatalf("expected 3 statements, got %d", results.Len())
+	}
+	if results.Statements[0].Status != "OK" || results.Statements[1].Status != "ERR" {
+		t.Fatalf("unexpected statuses %+v", results.Statements)
+	}
+	if results.Statements[0].Time != 1500*time.Microsecond || results.Duration() != 1750*time.Microsecond {
+		t.Fatalf("unexpected timings %s %s", results.Statements[0].Time, results.Duration())
+	}
+
+	var articles []Article
+	if err := results.Scan(0, &articles); err != nil || len(articles) != 1 || articles[0].Title != "first" {
+		t.Fatalf("unexpected first result %+v %v", articles, err)
+	}
+
+	var n int
+	if err := results.Scan(2, &n); err != nil || n != 3 {
+		t.Fatalf("unexpected third result %d %v", n, err)
+	}
+
+	var queryErr surreal.QueryError
+	if err := results.Scan(1, &n); !errors.As(err, &queryErr) || queryErr.QueryNo != 1 || !errors.Is(err, surreal.ErrAlreadyExists) {
+		t.Fatalf("expected the statement's error, got %v", err)
+	}
+	if err := results.Err(); !errors.Is(err, surreal.ErrAlreadyExists) {
+		t.Fatalf("expected QueryErrors, got %v", err)
+	}
+	if err := results.Scan(3, &n); err == nil {
+		t.Fatal("expected an error for a statement out of range")
+	}
+}
@@ -0,0 +1,154 @@
+package test
+
+import (
+	"context"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/schema"
+	"testing"
+	"time"
+)
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		statement schema.Statement
+		query     string
+	}{
+		{
+			schema.DefineNamespace("app").IfNotExists(),
+			"DEFINE NAMESPACE IF NOT EXISTS app",
+		},
+		{
+			schema.DefineDatabase("main").Changefeed(surreal.Duration(24 * time.Hour)).Comment("it's the main one"),
+			"DEFINE DATABASE main CHANGEFEED 1d COMMENT 'it\\'s the main one'",
+		},
+		{
+			schema.DefineTable("article").Schemafull().Overwrite().IfNotExists().Permissions(schema.Permissions{
+				Select: schema.PermitFull,
+				Create: schema.Where("$auth.id != NONE"),
+				Update: schema.Where("author = $auth.id"),
+				Delete: schema.Where("author = $auth.id"),
+			}),
+			"DEFINE TABLE OVERWRITE article SCHEMAFULL PERMISSIONS FOR select FULL, FOR create WHERE $auth.id != NONE, " +
+				"FOR update, delete WHERE author = $auth.id",
+		},
+		{
+			schema.DefineTable("likes").Relation([]string{"user"}, []string{"article", "comment"}).Enforced().Schemaless().
+				Changefeed(surreal.Duration(time.Hour), true).Permissions(schema.None()),
+			"DEFINE TABLE likes TYPE RELATION IN user OUT article | comment ENFORCED SCHEMALESS CHANGEFEED 1h " +
+				"INCLUDE ORIGINAL PERMISSIONS NONE",
+		},
+		{
+			schema.DefineTable("article count").Drop().As("SELECT count() FROM article GROUP ALL"),
+			"DEFINE TABLE ⟨article count⟩ DROP AS SELECT count() FROM article GROUP ALL",
+		},
+		{
+			schema.DefineField("title", "article").Type(schema.TypeString).Assert("string::len($value) > 0"),
+			"DEFINE FIELD title ON TABLE article TYPE string ASSERT string::len($value) > 0",
+		},
+		{
+			schema.DefineField("tags[*]", "article").Type(schema.Option(schema.Record("tag", "topic"))).
+				DefaultAlways("[]").Readonly().Value("array::distinct($value)").
+				Permissions(schema.Permissions{Select: schema.PermitFull, Create: schema.PermitFull, Update: schema.PermitNone}),
+			"DEFINE FIELD tags[*] ON TABLE article TYPE option<record<tag | topic>> DEFAULT ALWAYS [] READONLY " +
+				"VALUE array::distinct($value) PERMISSIONS FOR select, create FULL, FOR update NONE",
+		},
+		{
+			schema.DefineField("meta.first-seen", "article").Flexible().Type(schema.Either(schema.TypeDatetime, schema.ArrayMax(schema.TypeInt, 3))).
+				Permissions(schema.Full()),
+			"DEFINE FIELD meta.⟨first-seen⟩ ON TABLE article FLEXIBLE TYPE datetime | array<int, 3> PERMISSIONS FULL",
+		},
+		{
+			schema.DefineIndex("article_slug", "article").Fields("slug", "author").Unique().Comment("one slug per author"),
+			"DEFINE INDEX article_slug ON TABLE article FIELDS slug, author UNIQUE COMMENT 'one slug per author'",
+		},
+		{
+			schema.DefineIndex("article_body", "article").Fields("body").Search("english").BM25(1.2, 0.75).Highlights(),
+			"DEFINE INDEX article_body ON TABLE article FIELDS body SEARCH ANALYZER english BM25(1.2,0.75) HIGHLIGHTS",
+		},
+		{
+			schema.DefineIndex("article_embedding", "article").Fields("embedding").HNSW(768).Distance(schema.DistanceCosine).EFC(150, 12),
+			"DEFINE INDEX article_embedding ON TABLE article FIELDS embedding HNSW DIMENSION 768 DIST COSINE EFC 150 M 12",
+		},
+		{
+			schema.DefineIndex("venue_location", "venue").Fields("location").MTree(2).IfNotExists(),
+			"DEFINE INDEX IF NOT EXISTS venue_location ON TABLE venue FIELDS location MTREE DIMENSION 2",
+		},
+		{
+			schema.DefineAnalyzer("english").Tokenizers("blank", "class").Filters("lowercase", "snowball(english)"),
+			"DEFINE ANALYZER english TOKENIZERS blank,class FILTERS lowercase,snowball(english)",
+		},
+		{
+			schema.DefineEvent("email_changed", "user").When("$before.email != $after.email").
+				Then("CREATE event SET user = $value.id, time = time::now()"),
+			"DEFINE EVENT email_changed ON TABLE user WHEN $before.email != $after.email " +
+				"THEN (CREATE event SET user = $value.id, time = time::now())",
+		},
+		{
+			schema.DefineEvent("published", "article").When("$event = 'UPDATE'").
+				Then("LET $n = count($after.tags)", "UPDATE stats SET tagged += $n"),
+			"DEFINE EVENT published ON TABLE article WHEN $event = 'UPDATE' " +
+				"THEN { LET $n = count($after.tags); UPDATE stats SET tagged += $n }",
+		},
+		{
+			schema.DefineAccess("account", schema.OnDatabase).
+				Record("CREATE user SET email = $email", "SELECT * FROM user WHERE email = $email").
+				JWT("HS512", "secret").
+				Duration(surreal.Duration(time.Hour), surreal.Duration(12*time.Hour)),
+			"DEFINE ACCESS account ON DATABASE TYPE RECORD SIGNUP (CREATE user SET email = $email) " +
+				"SIGNIN (SELECT * FROM user WHERE email = $email) WITH JWT ALGORITHM HS512 KEY 'secret' " +
+				"DURATION FOR TOKEN 1h, FOR SESSION 12h",
+		},
+		{
+			schema.DefineAccess("issuer", schema.OnNamespace).JWKS("https://example.com/.well-known/jwks.json").
+				Duration(0, surreal.Duration(time.Hour)),
+			"DEFINE ACCESS issuer ON NAMESPACE TYPE JWT URL 'https://example.com/.well-known/jwks.json' " +
+				"DURATION FOR SESSION 1h",
+		},
+	}
+
+	for _, test := range tests {
+		query, vars, err := test.statement.Build()
+		if err != nil {
+			t.Errorf("unexpected Build error: %s", err)
+		}
+		if query != test.query {
+			t.Errorf("unexpected query:\n%s\nexpected:\n%s", query, test.query)
+		}
+		if vars != nil {
+			t.Errorf("unexpected vars %v for %s", vars, query)
+		}
+	}
+
+	rendered := schema.Render(schema.DefineTable("tag"), schema.DefineField("name", "tag").Type(schema.TypeString))
+	if rendered != "DEFINE TABLE tag;\nDEFINE FIELD name ON TABLE tag TYPE string;" {
+		t.Fatalf("unexpected rendered schema:\n%s", rendered)
+	}
+}
+
+func TestSchemaApply(t *testing.T) {
+	var queries []string
+	db := serveRPC(t, func(method string, params []any) (any, *rpc.Error) {
+		queries = append(queries, params[0].(string))
+		return []surreal.Map{
+			{"status": "OK", "time": "1ms", "result": nil},
+			{"status": "OK", "time": "1ms", "result": nil},
+		}, nil
+	})
+
+	err := schema.Apply(context.Background(), db,
+		schema.DefineTable("tag").Schemafull(),
+		schema.DefineField("name", "tag").Type(schema.TypeString).Assert("$value != ''"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected Apply error: %s", err)
+	}
+
+	expected := "BEGIN TRANSACTION;\n" +
+		"DEFINE TABLE tag SCHEMAFULL;\n" +
+		"DEFINE FIELD name ON TABLE tag TYPE string ASSERT $value != '';\n" +
+		"COMMIT TRANSACTION;"
+	if len(queries) != 1 || queries[0] != expected {
+		t.Fatalf("unexpected queries %q", queries)
+	}
+}
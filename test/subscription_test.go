@@ -0,0 +1,287 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// serveLive runs a websocket server answering live with the id "live-1" and, on each version call, pushing count
+// notifications for it before replying.
+func serveLive(t *testing.T, count int, kills *[]any) *surreal.DB {
+	var lock sync.Mutex
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var outgoing rpc.Outgoing
+			if err := json.Unmarshal(msg, &outgoing); err != nil {
+				t.Errorf("failed to decode request: %s", err)
+				return
+			}
+
+			var result any
+			switch outgoing.Method {
+			case "live":
+				result = "live-1"
+			case "kill":
+				lock.Lock()
+				*kills = append(*kills, outgoing.Params[0])
+				lock.Unlock()
+			case "version":
+				for i := 0; i < count; i++ {
+					_ = conn.WriteJSON(surreal.Map{"result": surreal.Map{"id": "live-1", "action": "CREATE", "result": surreal.Map{"id": "article:" + strconv.Itoa(i), "n": i}}})
+				}
+				result = "surrealdb-test"
+			}
+
+			_ = conn.WriteJSON(surreal.Map{"id": outgoing.ID, "result": result})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	db, err := surreal.Connect("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func TestSubscription(t *testing.T) {
+	var kills []any
+	db := serveLive(t, 100, &kills)
+
+	sub, err := db.Subscribe("article", &surreal.SubscriptionOptions{BufferSize: 8})
+	if err != nil {
+		t.Fatalf("unexpected Subscribe error: %s", err)
+	}
+	if sub.ID() != "live-1" {
+		t.Fatalf("expected live-1, got %s", sub.ID())
+	}
+
+	go func() {
+		if _, err := db.Version(); err != nil {
+			t.Errorf("unexpected Version error: %s", err)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		select {
+		case notification := <-sub.Notifications():
+			var record struct {
+				N int `json:"n"`
+			}
+			if err := json.Unmarshal(notification.Result, &record); err != nil {
+				t.Fatalf("failed to decode notification: %s", err)
+			}
+			if record.N != i {
+				t.Fatalf("expected notification %d, got %d", i, record.N)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for notification %d", i)
+		}
+	}
+
+	if err := sub.Err(); err != nil {
+		t.Fatalf("expected no error while running, got %s", err)
+	}
+	if err := sub.Close(); err != nil {
+		t.Fatalf("unexpected Close error: %s", err)
+	}
+	if _, open := <-sub.Notifications(); open {
+		t.Fatalf("expected notifications channel to be closed")
+	}
+	if !errors.Is(sub.Err(), surreal.ErrSubscriptionClosed) {
+		t.Fatalf("expected ErrSubscriptionClosed, got %v", sub.Err())
+	}
+	if len(kills) != 1 || kills[0] != "live-1" {
+		t.Fatalf("expected live-1 to be killed, got %v", kills)
+	}
+}
+
+func TestSubscriptionOverflow(t *testing.T) {
+	var kills []any
+	db := serveLive(t, 10, &kills)
+
+	dropping, err := db.Subscribe("article", &surreal.SubscriptionOptions{BufferSize: 4, Overflow: surreal.OverflowDropOldest})
+	if err != nil {
+		t.Fatalf("unexpected Subscribe error: %s", err)
+	}
+
+	if _, err := db.Version(); err != nil {
+		t.Fatalf("unexpected Version error: %s", err)
+	}
+
+	// notifications are dispatched apart from responses, so they may still be on their way once Version returns
+	deadline := time.Now().Add(5 * time.Second)
+	for dropping.Dropped() != 6 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if dropping.Dropped() != 6 {
+		t.Fatalf("expected 6 dropped notifications, got %d", dropping.Dropped())
+	}
+	first := <-dropping.Notifications()
+	if !strings.Contains(string(first.Result), `"n":6}`) {
+		t.Fatalf("expected the oldest notifications to be dropped, got %s", first.Result)
+	}
+	_ = dropping.Close()
+
+	closing, err := db.Subscribe("article", &surreal.SubscriptionOptions{BufferSize: 4, Overflow: surreal.OverflowClose})
+	if err != nil {
+		t.Fatalf("unexpected Subscribe error: %s", err)
+	}
+
+	if _, err := db.Version(); err != nil {
+		t.Fatalf("unexpected Version error: %s", err)
+	}
+
+	deadline = time.Now().Add(5 * time.Second)
+	for closing.Err() == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	received := 0
+	for range closing.Notifications() {
+		received++
+	}
+	if received != 4 {
+		t.Fatalf("expected 4 buffered notifications, got %d", received)
+	}
+	if !errors.Is(closing.Err(), surreal.ErrSubscriptionOverflow) {
+		t.Fatalf("expected ErrSubscriptionOverflow, got %v", closing.Err())
+	}
+}
+
+func TestSubscribeAs(t *testing.T) {
+	var kills []any
+	db := serveLive(t, 3, &kills)
+
+	sub, err := surreal.SubscribeAs[struct {
+		N int `json:"n"`
+	}](db, "article", nil)
+	if err != nil {
+		t.Fatalf("unexpected SubscribeAs error: %s", err)
+	}
+	defer sub.Close()
+
+	if _, err := db.Version(); err != nil {
+		t.Fatalf("unexpected Version error: %s", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		event := <-sub.Events()
+		if event.Action != rpc.Create || event.QueryID != "live-1" {
+			t.Fatalf("unexpected event %+v", event)
+		}
+		if event.Record.N != i {
+			t.Fatalf("expected record %d, got %d", i, event.Record.N)
+		}
+		if expected := surreal.NewRecordID("article", int64(i)); !event.RecordID.Equal(expected) {
+			t.Fatalf("expected record id %s, got %s", expected, event.RecordID)
+		}
+	}
+}
+
+func TestSubscriptionNotDrained(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	dropped := make(chan error, 1)
+	db, err := surreal.Connect(server.URL, &surreal.Options{
+		WebSocketOptions: surreal.WebSocketOptions{
+			ResponseTimeout: 2 * time.Second,
+			PingInterval:    10 * time.Millisecond,
+			PongTimeout:     50 * time.Millisecond,
+			OnDropCallback:  func(reason error) { dropped <- reason },
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	blocking, err := db.Subscribe("article", &surreal.SubscriptionOptions{BufferSize: 1})
+	if err != nil {
+		t.Fatalf("unexpected Subscribe error: %s", err)
+	}
+	dropping, err := db.Subscribe("article", &surreal.SubscriptionOptions{BufferSize: 1, Overflow: surreal.OverflowDropNewest})
+	if err != nil {
+		t.Fatalf("unexpected Subscribe error: %s", err)
+	}
+	defer dropping.Close()
+
+	// neither subscription is ever drained, which must not hold back responses nor pongs
+	for i := 0; i < 600; i++ {
+		if err := db.Create("article:"+strconv.Itoa(i), surreal.Map{"n": i}); err != nil {
+			t.Fatalf("unexpected Create error after %d records: %s", i, err)
+		}
+		if err := db.Ping(); err != nil {
+			t.Fatalf("unexpected Ping error after %d records: %s", i, err)
+		}
+	}
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if err := db.Ping(); err != nil {
+			t.Fatalf("unexpected Ping error: %s", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case reason := <-dropped:
+		t.Fatalf("expected the connection to stay up, dropped with %v", reason)
+	default:
+	}
+
+	if !errors.Is(blocking.Err(), surreal.ErrSubscriptionOverflow) {
+		t.Fatalf("expected the blocking subscription to overflow its backlog, got %v", blocking.Err())
+	}
+	if dropping.Err() != nil || dropping.Dropped() == 0 {
+		t.Fatalf("expected the dropping subscription to keep running, got %v after %d drops", dropping.Err(), dropping.Dropped())
+	}
+}
+
+func TestDecodeLiveEventDiff(t *testing.T) {
+	var kills []any
+	db := serveLive(t, 0, &kills)
+
+	event, err := surreal.DecodeLiveEvent[Article](db, rpc.LiveNotification{
+		ID:     "live-1",
+		Action: rpc.Update,
+		Record: rpc.RawMessage(`"article:1"`),
+		Result: rpc.RawMessage(`[{"op": "replace", "path": "/title", "value": "changed"}]`),
+	}, true)
+	if err != nil {
+		t.Fatalf("unexpected DecodeLiveEvent error: %s", err)
+	}
+	if len(event.Diff) != 1 || event.Diff[0].Op != "replace" || event.Diff[0].Path != "/title" || event.Diff[0].Value != "changed" {
+		t.Fatalf("expected the diff to be decoded, got %+v", event.Diff)
+	}
+	if !event.RecordID.Equal(surreal.NewRecordID("article", int64(1))) {
+		t.Fatalf("unexpected record id %s", event.RecordID)
+	}
+}
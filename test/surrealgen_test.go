@@ -0,0 +1,236 @@
+package test
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealgen"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseType(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected *surrealgen.Type
+	}{
+		{"string", &surrealgen.Type{Kind: "string"}},
+		{"option<array<record<user | team>>>", &surrealgen.Type{Kind: "option", Elem: &surrealgen.Type{
+			Kind: "array",
+			Elem: &surrealgen.Type{Kind: "record", Tables: []string{"user", "team"}},
+		}}},
+		{"array<string, 10>", &surrealgen.Type{Kind: "array", Elem: &surrealgen.Type{Kind: "string"}}},
+		{"geometry<point>", &surrealgen.Type{Kind: "geometry", Tables: []string{"point"}}},
+		{"'draft' | 'published'", &surrealgen.Type{Kind: "either", Variants: []*surrealgen.Type{
+			{Kind: "literal", Literal: "'draft'"},
+			{Kind: "literal", Literal: "'published'"},
+		}}},
+		{"none | int", &surrealgen.Type{Kind: "either", Variants: []*surrealgen.Type{{Kind: "none"}, {Kind: "int"}}}},
+	}
+
+	for _, c := range cases {
+		parsed, err := surrealgen.ParseType(c.input)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %s", c.input, err)
+		}
+		if !reflect.DeepEqual(parsed, c.expected) {
+			t.Fatalf("parsed %s into %s, expected %s", c.input, parsed, c.expected)
+		}
+	}
+
+	for _, input := range []string{"", "option<string", "record<>"} {
+		if _, err := surrealgen.ParseType(input); err == nil {
+			t.Fatalf("expected %q not to parse", input)
+		}
+	}
+}
+
+func TestParseField(t *testing.T) {
+	field, err := surrealgen.ParseField("DEFINE FIELD OVERWRITE ⟨first name⟩ ON TABLE person TYPE option<string | int> " +
+		"DEFAULT 'x' READONLY ASSERT $value < 10 COMMENT 'shown <here>' PERMISSIONS FULL")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if field.Name() != "⟨first name⟩" || field.Type.String() != "option<string | int>" || !field.Readonly || field.Comment != "shown <here>" {
+		t.Fatalf("unexpected field %+v", field)
+	}
+
+	field, err = surrealgen.ParseField("DEFINE FIELD tags[*] ON post TYPE string PERMISSIONS FULL")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(field.Path, []string{"tags", "*"}) || field.Name() != "tags[*]" {
+		t.Fatalf("unexpected field %+v", field)
+	}
+
+	for _, definition := range []string{
+		"DEFINE FIELD",
+		"DEFINE FIELD x ON",
+		"DEFINE FIELD x ON TABLE",
+		"DEFINE FIELD IF NOT EXISTS x ON TABLE",
+		"DEFINE FIELD OVERWRITE x ON TABLE",
+		"DEFINE FIELD x ON TABLE person COMMENT",
+		"DEFINE FIELD x ON TABLE person TYPE",
+	} {
+		if _, err := surrealgen.ParseField(definition); err == nil {
+			t.Errorf("expected %q not to parse", definition)
+		}
+	}
+
+	// no prefix of a definition may make the parser panic
+	words := strings.Fields("DEFINE FIELD IF NOT EXISTS address.city ON TABLE person FLEXIBLE TYPE option<string> " +
+		"DEFAULT 'x' READONLY VALUE $value ASSERT $value != NONE COMMENT 'c' PERMISSIONS FULL")
+	for i := range words {
+		_, _ = surrealgen.ParseField(strings.Join(words[:i], " "))
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	server.Handle("query", func(params []any) (any, *rpc.Error) {
+		switch params[0] {
+		case "INFO FOR DB":
+			return surrealtest.QueryResult(map[string]any{"tables": map[string]any{
+				"blog_post": "DEFINE TABLE blog_post TYPE NORMAL SCHEMAFULL PERMISSIONS NONE",
+				"wrote":     "DEFINE TABLE wrote TYPE RELATION IN user OUT blog_post SCHEMAFULL PERMISSIONS NONE",
+			}}), nil
+		case "INFO FOR TABLE blog_post; INFO FOR TABLE wrote":
+			return surrealtest.QueryResult(
+				map[string]any{"fields": map[string]any{
+					"title":        "DEFINE FIELD title ON blog_post TYPE string PERMISSIONS FULL",
+					"status":       "DEFINE FIELD status ON blog_post TYPE 'draft' | 'published' PERMISSIONS FULL",
+					"author":       "DEFINE FIELD author ON blog_post TYPE record<user> PERMISSIONS FULL",
+					"createdAt":    "DEFINE FIELD createdAt ON blog_post TYPE datetime READONLY VALUE time::now() PERMISSIONS FULL",
+					"views":        "DEFINE FIELD views ON blog_post TYPE option<int> COMMENT 'Times the post was read' PERMISSIONS FULL",
+					"tags":         "DEFINE FIELD tags ON blog_post TYPE array<string> PERMISSIONS FULL",
+					"tags[*]":      "DEFINE FIELD tags[*] ON blog_post TYPE string PERMISSIONS FULL",
+					"location":     "DEFINE FIELD location ON blog_post TYPE option<geometry<point>> PERMISSIONS FULL",
+					"meta":         "DEFINE FIELD meta ON blog_post FLEXIBLE TYPE object PERMISSIONS FULL",
+					"seo":          "DEFINE FIELD seo ON blog_post TYPE option<object> PERMISSIONS FULL",
+					"seo.title":    "DEFINE FIELD seo.title ON blog_post TYPE string PERMISSIONS FULL",
+					"seo.keywords": "DEFINE FIELD seo.keywords ON blog_post TYPE array<string> PERMISSIONS FULL",
+				}},
+				map[string]any{"fields": map[string]any{
+					"in":  "DEFINE FIELD in ON wrote TYPE record<user> PERMISSIONS FULL",
+					"out": "DEFINE FIELD out ON wrote TYPE record<blog_post> PERMISSIONS FULL",
+				}},
+			), nil
+		default:
+			return nil, &rpc.Error{Code: -32000, Message: "unexpected query"}
+		}
+	})
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	schema, err := surrealgen.Inspect(db)
+	if err != nil {
+		t.Fatalf("unexpected Inspect error: %s", err)
+	}
+	if len(schema.Tables) != 2 || !schema.Tables[1].Relation {
+		t.Fatalf("unexpected schema %+v", schema)
+	}
+
+	source, err := surrealgen.Generate(schema, &surrealgen.Options{Package: "models"})
+	if err != nil {
+		t.Fatalf("unexpected Generate error: %s", err)
+	}
+
+	expected := "// Code generated by surreal-gen. DO NOT EDIT.\n" + `
+package models
+
+import "github.com/terawatthour/surreal-go"
+
+const (
+	// TableBlogPost is the blog_post table.
+	TableBlogPost surreal.Table = "blog_post"
+	// TableWrote is the wrote table.
+	TableWrote surreal.Table = "wrote"
+)
+
+// BlogPost is a record of the blog_post table.
+type BlogPost struct {
+	ID        surreal.RecordID ` + "`" + `surreal:"id,readonly"` + "`" + `
+	Author    surreal.RecordID ` + "`" + `surreal:"author"` + "`" + `
+	CreatedAt surreal.Datetime ` + "`" + `surreal:"createdAt,readonly"` + "`" + `
+	Location  *surreal.Point   ` + "`" + `surreal:"location,omitempty"` + "`" + `
+	Meta      map[string]any   ` + "`" + `surreal:"meta"` + "`" + `
+	Seo       *BlogPostSeo     ` + "`" + `surreal:"seo,omitempty"` + "`" + `
+	Status    string           ` + "`" + `surreal:"status"` + "`" + `
+	Tags      []string         ` + "`" + `surreal:"tags"` + "`" + `
+	Title     string           ` + "`" + `surreal:"title"` + "`" + `
+	// Times the post was read
+	Views *int64 ` + "`" + `surreal:"views,omitempty"` + "`" + `
+}
+
+// BlogPostSeo is an object.
+type BlogPostSeo struct {
+	Keywords []string ` + "`" + `surreal:"keywords"` + "`" + `
+	Title    string   ` + "`" + `surreal:"title"` + "`" + `
+}
+
+// Wrote is a record of the wrote table.
+type Wrote struct {
+	ID  surreal.RecordID ` + "`" + `surreal:"id,readonly"` + "`" + `
+	In  surreal.RecordID ` + "`" + `surreal:"in"` + "`" + `
+	Out surreal.RecordID ` + "`" + `surreal:"out"` + "`" + `
+}
+`
+	if string(source) != expected {
+		t.Fatalf("unexpected generated code:\n%s", source)
+	}
+}
+
+func TestGenerateClashingNames(t *testing.T) {
+	schema := &surrealgen.Schema{Tables: []surrealgen.Table{
+		{Name: "user_profile"},
+		{Name: "userProfile"},
+		{Name: "x"},
+		{Name: "table_x"},
+	}}
+
+	source, err := surrealgen.Generate(schema, nil)
+	if err != nil {
+		t.Fatalf("unexpected Generate error: %s", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "schema.go", source, 0)
+	if err != nil {
+		t.Fatalf("failed to parse generated code: %s", err)
+	}
+
+	declared := map[string]bool{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			var names []*ast.Ident
+			switch spec := spec.(type) {
+			case *ast.ValueSpec:
+				names = spec.Names
+			case *ast.TypeSpec:
+				names = []*ast.Ident{spec.Name}
+			}
+			for _, name := range names {
+				if declared[name.Name] {
+					t.Fatalf("%s is declared twice in:\n%s", name.Name, source)
+				}
+				declared[name.Name] = true
+			}
+		}
+	}
+	if len(declared) != 8 {
+		t.Fatalf("expected a constant and a struct for each table, got %v", declared)
+	}
+}
@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealotel"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSurrealotel(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	options := &surreal.Options{}
+	err := surrealotel.Instrument(options,
+		surrealotel.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		surrealotel.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		surrealotel.WithStatementRedactor(func(statement string) string {
+			return strings.ReplaceAll(statement, "secret", "?")
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected Instrument error: %s", err)
+	}
+
+	db, err := surreal.Connect(server.URL, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	if err := db.Use("test", "test"); err != nil {
+		t.Fatalf("unexpected Use error: %s", err)
+	}
+
+	server.Handle("query", func(params []any) (any, *rpc.Error) {
+		return []map[string]any{{"status": "OK", "time": "1.5ms", "result": nil}, {"status": "OK", "time": "500µs", "result": nil}}, nil
+	})
+	if err := db.Query("RETURN 'secret'; RETURN 1", nil); err != nil {
+		t.Fatalf("unexpected Query error: %s", err)
+	}
+
+	if _, err := surreal.SelectOne[Article](db, "article:missing"); !errors.Is(err, surreal.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	server.Fail("select", &rpc.Error{Code: -32000, Message: "IAM error: Not enough permissions"})
+	if _, err := surreal.SelectOne[Article](db, "article:1"); !errors.Is(err, surreal.ErrPermission) {
+		t.Fatalf("expected ErrPermission, got %v", err)
+	}
+
+	sub, err := db.Subscribe("article", &surreal.SubscriptionOptions{BufferSize: 1, Overflow: surreal.OverflowDropNewest})
+	if err != nil {
+		t.Fatalf("unexpected Subscribe error: %s", err)
+	}
+	defer sub.Close()
+	for _, id := range []string{"article:1", "article:2"} {
+		if err := db.Create(id, Article{Title: id}); err != nil {
+			t.Fatalf("unexpected Create error: %s", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for sub.Dropped() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	ended := spans.Ended()
+	byName := map[string][]sdktrace.ReadOnlySpan{}
+	for _, span := range ended {
+		byName[span.Name()] = append(byName[span.Name()], span)
+	}
+
+	query := byName["query"]
+	if len(query) != 1 {
+		t.Fatalf("expected a query span, got %d", len(query))
+	}
+	attributes := attributeMap(query[0].Attributes())
+	if attributes["db.query.text"].AsString() != "RETURN '?'; RETURN 1" {
+		t.Fatalf("expected the redacted statement, got %q", attributes["db.query.text"].AsString())
+	}
+	if attributes["db.surrealdb.namespace"].AsString() != "test" || attributes["db.surrealdb.database"].AsString() != "test" {
+		t.Fatalf("expected the namespace and database to be recorded, got %v", attributes)
+	}
+	if attributes["db.surrealdb.server_duration"].AsFloat64() != 0.002 || attributes["db.surrealdb.statement_count"].AsInt64() != 2 {
+		t.Fatalf("unexpected server duration %v", attributes)
+	}
+
+	selects := byName["select"]
+	if len(selects) != 2 || selects[1].Status().Code != codes.Error {
+		t.Fatalf("expected the failed select to be marked, got %d spans", len(selects))
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &metrics); err != nil {
+		t.Fatalf("unexpected Collect error: %s", err)
+	}
+	sums := map[string]int64{}
+	histograms := map[string]uint64{}
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, point := range data.DataPoints {
+					sums[m.Name] += point.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, point := range data.DataPoints {
+					if kind, ok := point.Attributes.Value("error.type"); ok {
+						histograms[kind.AsString()] += point.Count
+					}
+				}
+			}
+		}
+	}
+
+	if sums["surrealdb.client.requests.in_flight"] != 0 {
+		t.Fatalf("expected no calls in flight, got %d", sums["surrealdb.client.requests.in_flight"])
+	}
+	if sums["surrealdb.client.live.notifications"] != 2 || sums["surrealdb.client.live.dropped"] != 1 {
+		t.Fatalf("unexpected live metrics %v", sums)
+	}
+	if histograms["permission"] != 1 {
+		t.Fatalf("expected the failed select to be classified, got %v", histograms)
+	}
+}
+
+func attributeMap(attributes []attribute.KeyValue) map[string]attribute.Value {
+	m := make(map[string]attribute.Value, len(attributes))
+	for _, kv := range attributes {
+		m[string(kv.Key)] = kv.Value
+	}
+	return m
+}
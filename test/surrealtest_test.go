@@ -0,0 +1,84 @@
+package test
+
+import (
+	"errors"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"testing"
+	"time"
+)
+
+func TestSurrealtestServer(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	reconnected := make(chan error, 1)
+	db, err := surreal.Connect(server.URL, &surreal.Options{
+		WebSocketOptions: surreal.WebSocketOptions{
+			Reconnect:           true,
+			ReconnectBackoff:    time.Millisecond,
+			OnReconnectCallback: func(err error) { reconnected <- err },
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	events, err := surreal.SubscribeAs[Article](db, "article", nil)
+	if err != nil {
+		t.Fatalf("unexpected SubscribeAs error: %s", err)
+	}
+	defer events.Close()
+
+	if err := db.Create("article:first", Article{Title: "first"}); err != nil {
+		t.Fatalf("unexpected Create error: %s", err)
+	}
+	if err := db.Create("article:first", Article{Title: "again"}); !errors.Is(err, surreal.ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+
+	event := <-events.Events()
+	if event.Action != rpc.Create || event.Record.Title != "first" || event.RecordID.String() != "article:first" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	if record, ok := server.Get("article:first"); !ok || record["title"] != "first" {
+		t.Fatalf("unexpected stored record %v", record)
+	}
+
+	server.Fail("select", &rpc.Error{Code: -32000, Message: "IAM error: Not enough permissions"})
+	if _, err := surreal.SelectOne[Article](db, "article:first"); !errors.Is(err, surreal.ErrPermission) {
+		t.Fatalf("expected the injected failure, got %v", err)
+	}
+	if article, err := surreal.SelectOne[Article](db, "article:first"); err != nil || article.Title != "first" {
+		t.Fatalf("expected the failure to be used up, got %+v %v", article, err)
+	}
+
+	server.Handle("query", func(params []any) (any, *rpc.Error) {
+		return surrealtest.QueryResult(params[1].(map[string]any)["n"]), nil
+	})
+	var n int
+	if err := db.Query("RETURN $n", surreal.Map{"n": 7}, &n); err != nil || n != 7 {
+		t.Fatalf("unexpected scripted query result %d %v", n, err)
+	}
+
+	server.DropConnections()
+	select {
+	case err := <-reconnected:
+		if err != nil {
+			t.Fatalf("unexpected reconnect error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnect")
+	}
+
+	if err := db.Merge("article:first", surreal.Map{"content": "merged"}); err != nil {
+		t.Fatalf("unexpected Merge error: %s", err)
+	}
+	event = <-events.Events()
+	if event.Action != rpc.Update || event.Record.Title != "first" || event.Record.Content != "merged" {
+		t.Fatalf("expected the live query to survive the reconnect, got %+v", event)
+	}
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"testing"
+)
+
+type TaggedAuthor struct {
+	ID   surreal.RecordID `surreal:"id,readonly"`
+	Name string           `surreal:"name"`
+}
+
+type TaggedPost struct {
+	ID       string           `surreal:"id,readonly,recordid"`
+	Title    string           `surreal:"title"`
+	Subtitle string           `surreal:"subtitle,omitempty"`
+	Views    int              `surreal:"views,readonly"`
+	Author   TaggedAuthor     `surreal:"author,fetch"`
+	Editor   surreal.RecordID `surreal:"editor,fetch"`
+	Legacy   string           `json:"legacy"`
+	Ignored  string           `surreal:"-"`
+}
+
+func TestStructTags(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	post := TaggedPost{
+		ID:      "post:ignored",
+		Title:   "Tags",
+		Views:   10,
+		Author:  TaggedAuthor{ID: surreal.NewRecordID("user", "jane"), Name: "Jane"},
+		Editor:  surreal.NewRecordID("user", "john"),
+		Legacy:  "kept",
+		Ignored: "dropped",
+	}
+	if err := db.Create("post:1", post); err != nil {
+		t.Fatalf("unexpected Create error: %s", err)
+	}
+
+	stored, _ := server.Get("post:1")
+	for _, field := range []string{"views", "subtitle", "Ignored"} {
+		if _, ok := stored[field]; ok {
+			t.Fatalf("expected %s not to be sent, got %v", field, stored)
+		}
+	}
+	if stored["id"] != "post:1" || stored["author"] != "user:jane" || stored["editor"] != "user:john" || stored["legacy"] != "kept" {
+		t.Fatalf("unexpected stored record %v", stored)
+	}
+
+	selected, err := surreal.SelectOne[TaggedPost](db, "post:1")
+	if err != nil {
+		t.Fatalf("unexpected Select error: %s", err)
+	}
+	if selected.ID != "post:1" || selected.Title != "Tags" || selected.Legacy != "kept" {
+		t.Fatalf("unexpected selected post %+v", selected)
+	}
+	if !selected.Author.ID.Equal(surreal.NewRecordID("user", "jane")) || selected.Author.Name != "" {
+		t.Fatalf("expected only the id of the unfetched author, got %+v", selected.Author)
+	}
+
+	server.Handle("query", func(params []any) (any, *rpc.Error) {
+		return surrealtest.QueryResult([]map[string]any{{
+			"id":     "post:1",
+			"title":  "Tags",
+			"views":  3,
+			"author": map[string]any{"id": "user:jane", "name": "Jane"},
+			"editor": map[string]any{"id": "user:john", "name": "John"},
+		}}), nil
+	})
+
+	var fetched []TaggedPost
+	if err := db.Query("SELECT * FROM post FETCH author, editor", nil, &fetched); err != nil {
+		t.Fatalf("unexpected Query error: %s", err)
+	}
+	if len(fetched) != 1 {
+		t.Fatalf("unexpected result %+v", fetched)
+	}
+	if fetched[0].Views != 3 || fetched[0].Author.Name != "Jane" || !fetched[0].Author.ID.Equal(surreal.NewRecordID("user", "jane")) {
+		t.Fatalf("expected the fetched author to be decoded, got %+v", fetched[0])
+	}
+	if !fetched[0].Editor.Equal(surreal.NewRecordID("user", "john")) {
+		t.Fatalf("expected the id of the fetched editor, got %+v", fetched[0].Editor)
+	}
+}
+
+type TaggedNode struct {
+	Next *TaggedNode
+	ID   string `surreal:"id,readonly"`
+	Name string `surreal:"name"`
+}
+
+func TestStructTagsSelfReferencing(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	node := TaggedNode{ID: "node:ignored", Name: "outer", Next: &TaggedNode{ID: "node:9", Name: "inner"}}
+	if err := db.Create("node:1", node); err != nil {
+		t.Fatalf("unexpected Create error: %s", err)
+	}
+
+	stored, _ := server.Get("node:1")
+	next, ok := stored["Next"].(map[string]any)
+	if !ok || stored["name"] != "outer" {
+		t.Fatalf("unexpected stored record %v", stored)
+	}
+	if _, ok := next["id"]; ok || next["name"] != "inner" || next["Name"] != nil {
+		t.Fatalf("expected the nested record to be encoded with its tags, got %v", next)
+	}
+
+	selected, err := surreal.SelectOne[TaggedNode](db, "node:1")
+	if err != nil {
+		t.Fatalf("unexpected Select error: %s", err)
+	}
+	if selected.ID != "node:1" || selected.Next == nil || selected.Next.Name != "inner" || selected.Next.ID != "" {
+		t.Fatalf("unexpected selected node %+v", selected)
+	}
+}
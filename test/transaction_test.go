@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"testing"
+	"time"
+)
+
+func TestTransaction(t *testing.T) {
+	var queries []string
+	var vars []map[string]any
+
+	db := serveRPC(t, func(method string, params []any) (any, *rpc.Error) {
+		queries = append(queries, params[0].(string))
+		vars = append(vars, params[1].(map[string]any))
+
+		if len(queries) == 1 {
+			return []surreal.Map{
+				{"status": "ERR", "time": "1ms", "result": "The query was not executed due to a failed transaction"},
+				{"status": "ERR", "time": "1ms", "result": "Failed to commit transaction due to a read or write conflict. This transaction can be retried"},
+			}, nil
+		}
+
+		return []surreal.Map{
+			{"status": "OK", "time": "1ms", "result": []surreal.Map{{"id": "account:a", "balance": 90}}},
+			{"status": "OK", "time": "1ms", "result": []surreal.Map{{"id": "account:b", "balance": 110}}},
+			{"status": "OK", "time": "1ms", "result": 2},
+		}, nil
+	})
+
+	var from, to []map[string]any
+	var count int
+	err := db.TransactionWithOptions(context.Background(), &surreal.TransactionOptions{MaxRetries: 1, RetryBackoff: time.Millisecond}, func(tx *surreal.Tx) error {
+		tx.Query("UPDATE account:a SET balance -= $amount; -- don't touch $amount in comments", surreal.Map{"amount": 10}, &from)
+		tx.Query("UPDATE account:b SET balance += $amount; RETURN '$amount'", surreal.Map{"amount": 20}, &to, &count)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected Transaction error: %s", err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("expected the conflicting transaction to be retried once, got %d attempts", len(queries))
+	}
+
+	expected := "BEGIN TRANSACTION;\n" +
+		"UPDATE account:a SET balance -= $amount;\n" +
+		"UPDATE account:b SET balance += $amount_1; RETURN '$amount';\n" +
+		"COMMIT TRANSACTION;"
+	if queries[1] != expected {
+		t.Fatalf("unexpected query:\n%s", queries[1])
+	}
+	if vars[1]["amount"] != float64(10) || vars[1]["amount_1"] != float64(20) {
+		t.Fatalf("unexpected vars %v", vars[1])
+	}
+
+	if len(from) != 1 || from[0]["balance"] != float64(90) || len(to) != 1 || to[0]["balance"] != float64(110) || count != 2 {
+		t.Fatalf("unexpected results %v %v %d", from, to, count)
+	}
+
+	sentinel := errors.New("insufficient funds")
+	err = db.Transaction(context.Background(), func(tx *surreal.Tx) error {
+		tx.Query("UPDATE account:a SET balance -= 1000", nil)
+		return sentinel
+	})
+	if !errors.Is(err, sentinel) || len(queries) != 2 {
+		t.Fatalf("expected the transaction to be abandoned with the returned error, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/terawatthour/surreal-go"
+	"github.com/terawatthour/surreal-go/rpc"
+	"github.com/terawatthour/surreal-go/surrealtest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		input     string
+		expected  time.Duration
+		formatted string
+	}{
+		{"1w2d3h", 9*24*time.Hour + 3*time.Hour, ""},
+		{"1y", 365 * 24 * time.Hour, ""},
+		{"1m30s", 90 * time.Second, ""},
+		{"90s", 90 * time.Second, "1m30s"},
+		{"1s500ms", 1500 * time.Millisecond, ""},
+		{"1.5ms", 1500 * time.Microsecond, "1ms500µs"},
+		{"3us", 3 * time.Microsecond, "3µs"},
+		{"-2h", -2 * time.Hour, ""},
+		{"0", 0, "0ns"},
+	}
+
+	for _, c := range cases {
+		parsed, err := surreal.ParseDuration(c.input)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %s", c.input, err)
+		}
+		if time.Duration(parsed) != c.expected {
+			t.Fatalf("parsed %s into %s, expected %s", c.input, time.Duration(parsed), c.expected)
+		}
+
+		formatted := c.formatted
+		if formatted == "" {
+			formatted = c.input
+		}
+		if parsed.String() != formatted {
+			t.Fatalf("formatted %s as %s, expected %s", c.input, parsed.String(), formatted)
+		}
+	}
+
+	for _, input := range []string{"", "1", "h", "1x", "1h2", "99999999999y"} {
+		if _, err := surreal.ParseDuration(input); err == nil {
+			t.Fatalf("expected %q not to parse", input)
+		}
+	}
+}
+
+type valueRecord struct {
+	Created surreal.Datetime `json:"created"`
+	TTL     surreal.Duration `json:"ttl"`
+	Price   surreal.Decimal  `json:"price"`
+	Token   surreal.UUID     `json:"token"`
+	Missing surreal.NoneType `json:"missing"`
+}
+
+func TestValues(t *testing.T) {
+	server := surrealtest.NewServer()
+	defer server.Close()
+
+	db, err := surreal.Connect(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	var received map[string]any
+	server.Handle("query", func(params []any) (any, *rpc.Error) {
+		received = params[1].(map[string]any)
+		return surrealtest.QueryResult(received), nil
+	})
+
+	token, _ := surreal.ParseUUID("01234567-89ab-cdef-0123-456789abcdef")
+	sent := valueRecord{
+		Created: surreal.Datetime{Time: time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)},
+		TTL:     surreal.Duration(9*24*time.Hour + 3*time.Hour),
+		Price:   "12.3456789012345678901234567890",
+		Token:   token,
+		Missing: surreal.None,
+	}
+
+	var echoed valueRecord
+	err = db.Query("RETURN { created: $created, ttl: $ttl, price: $price, token: $token, missing: $missing }", surreal.Map{
+		"created": sent.Created,
+		"ttl":     sent.TTL,
+		"price":   sent.Price,
+		"token":   sent.Token,
+		"missing": sent.Missing,
+	}, &echoed)
+	if err != nil {
+		t.Fatalf("unexpected Query error: %s", err)
+	}
+
+	expected := map[string]any{
+		"created": "2024-01-02T03:04:05.123456789Z",
+		"ttl":     "1w2d3h",
+		"price":   "12.3456789012345678901234567890",
+		"token":   "01234567-89ab-cdef-0123-456789abcdef",
+		"missing": nil,
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Fatalf("unexpected vars %v", received)
+	}
+	if echoed != sent {
+		t.Fatalf("unexpected result %+v", echoed)
+	}
+
+	var price surreal.Decimal
+	if err := json.Unmarshal([]byte("1.10000000000000000000000000001"), &price); err != nil || price != "1.10000000000000000000000000001" {
+		t.Fatalf("expected the digits of a JSON number to be kept, got %s, %v", price, err)
+	}
+	if rat, err := price.Rat(); err != nil || rat.FloatString(29) != "1.10000000000000000000000000001" {
+		t.Fatalf("unexpected exact value %v, %v", rat, err)
+	}
+	if _, err := surreal.ParseDecimal("1/3"); err == nil {
+		t.Fatalf("expected a fraction not to be a decimal")
+	}
+
+	if sent.Created.String() != "d'2024-01-02T03:04:05.123456789Z'" {
+		t.Fatalf("unexpected datetime literal %s", sent.Created)
+	}
+	id := surreal.NewRecordID("event", []any{sent.Created, sent.TTL, sent.Price, sent.Token, surreal.None})
+	formatted := "event:[d'2024-01-02T03:04:05.123456789Z', 1w2d3h, 12.3456789012345678901234567890dec, u'01234567-89ab-cdef-0123-456789abcdef', NONE]"
+	if id.String() != formatted {
+		t.Fatalf("unexpected record id literal %s", id)
+	}
+	parsed, err := surreal.ParseRecordID(formatted)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %s", formatted, err)
+	}
+	if parsed.String() != formatted {
+		t.Fatalf("unexpected round trip of %s: %s", formatted, parsed)
+	}
+}
@@ -1,13 +1,19 @@
 package test
 
 import (
+	"errors"
 	"fmt"
+	"github.com/gorilla/websocket"
 	"github.com/terawatthour/surreal-go"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestEstablishConnection(t *testing.T) {
-	db, err := surreal.Connect("ws://localhost:8000/rpc", &surreal.Options{
+	db, err := surreal.Connect(surrealURL, &surreal.Options{
 		Verbose: true,
 		WebSocketOptions: surreal.WebSocketOptions{
 			OnDropCallback: func(reason error) {
@@ -81,3 +87,45 @@ func TestEstablishConnection(t *testing.T) {
 	//
 	//fmt.Println(users)
 }
+
+func TestDeadPeer(t *testing.T) {
+	// the server never reads, so it never answers pings either
+	upgrader := websocket.Upgrader{}
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-stop
+	}))
+	defer server.Close()
+	defer close(stop)
+
+	dropped := make(chan error, 1)
+	db, err := surreal.Connect("ws"+strings.TrimPrefix(server.URL, "http"), &surreal.Options{
+		WebSocketOptions: surreal.WebSocketOptions{
+			PingInterval:   10 * time.Millisecond,
+			PongTimeout:    50 * time.Millisecond,
+			OnDropCallback: func(reason error) { dropped <- reason },
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+
+	select {
+	case reason := <-dropped:
+		if reason == nil {
+			t.Fatalf("expected a reason for the drop")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the silent server to be dropped")
+	}
+
+	if err := db.Ping(); !errors.Is(err, surreal.ErrClosed) {
+		t.Fatalf("expected ErrClosed after the drop, got %v", err)
+	}
+}
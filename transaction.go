@@ -0,0 +1,143 @@
+package surreal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultTransactionRetryBackoff = 50 * time.Millisecond
+
+type TransactionOptions struct {
+	// MaxRetries is the number of times a transaction that failed on a read or write conflict is run again, calling
+	// the function building it anew each time. Zero disables retrying.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry, doubled after each one. Defaults to 50ms.
+	RetryBackoff time.Duration
+}
+
+func (o *TransactionOptions) retryBackoff() time.Duration {
+	if o.RetryBackoff == 0 {
+		return DefaultTransactionRetryBackoff
+	}
+	return o.RetryBackoff
+}
+
+// Tx collects the statements of a transaction. Nothing is sent until the function passed to Transaction returns, so
+// destinations are only filled once the transaction has been committed.
+type Tx struct {
+	statements []string
+	vars       Map
+
+	// destinations holds, for every statement of the transaction, the destination its result is decoded into, if any
+	destinations []any
+}
+
+// Query adds a query (or multiple semicolon separated queries) to the transaction. Its results are decoded into the
+// corresponding scanDestinations once the transaction has been committed. Variables clashing with ones bound by
+// earlier queries of the transaction under a different value are renamed.
+func (tx *Tx) Query(query string, vars Map, scanDestinations ...any) {
+	renamed := make(map[string]string, len(vars))
+	for name, value := range vars {
+		key := name
+		for n := 1; ; n++ {
+			existing, taken := tx.vars[key]
+			if !taken || reflect.DeepEqual(existing, value) {
+				break
+			}
+			key = name + "_" + strconv.Itoa(n)
+		}
+		renamed[name] = key
+		tx.vars[key] = value
+	}
+
+	query, count := rewriteQuery(query, func(name string) string {
+		if key, ok := renamed[name]; ok {
+			return key
+		}
+		return name
+	})
+	if count == 0 {
+		return
+	}
+
+	tx.statements = append(tx.statements, strings.TrimSpace(query))
+	for i := 0; i < count; i++ {
+		var destination any
+		if i < len(scanDestinations) {
+			destination = scanDestinations[i]
+		}
+		tx.destinations = append(tx.destinations, destination)
+	}
+}
+
+// Transaction calls fn to collect statements, then runs them in a single BEGIN TRANSACTION ... COMMIT TRANSACTION
+// query. If fn returns an error nothing is sent and the error is returned as is. If any statement fails, the whole
+// transaction is cancelled and QueryErrors returned.
+func (db *DB) Transaction(ctx context.Context, fn func(tx *Tx) error) error {
+	return db.TransactionWithOptions(ctx, nil, fn)
+}
+
+// TransactionWithOptions is like Transaction, but retries transactions failing on read or write conflicts as
+// configured by options.
+func (db *DB) TransactionWithOptions(ctx context.Context, options *TransactionOptions, fn func(tx *Tx) error) error {
+	if options == nil {
+		options = &TransactionOptions{}
+	}
+	backoff := options.retryBackoff()
+
+	for attempt := 0; ; attempt++ {
+		err := db.transaction(ctx, fn)
+		if err == nil || attempt >= options.MaxRetries || !errors.Is(err, ErrTxConflict) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (db *DB) transaction(ctx context.Context, fn func(tx *Tx) error) error {
+	tx := &Tx{vars: Map{}}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if len(tx.statements) == 0 {
+		return nil
+	}
+
+	query := "BEGIN TRANSACTION;\n" + strings.Join(tx.statements, ";\n") + ";\nCOMMIT TRANSACTION;"
+	results, err := db.query(ctx, query, tx.vars)
+	if err != nil {
+		return err
+	}
+
+	// some server versions report results for BEGIN and COMMIT as well
+	offset := 0
+	if len(results) == len(tx.destinations)+2 {
+		offset = 1
+	}
+	if len(results) < offset+len(tx.destinations) {
+		return fmt.Errorf("expected %d results, got %d", len(tx.destinations), len(results))
+	}
+
+	for i, destination := range tx.destinations {
+		if destination == nil {
+			continue
+		}
+		if err := decode(db.codec, results[offset+i].Result, destination); err != nil {
+			return fmt.Errorf("failed to decode result of %d query: %s", i, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,558 @@
+package surreal
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/fxamacker/cbor/v2"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Table is the name of a table, as opposed to a string value.
+type Table string
+
+func (t Table) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagTable, string(t))
+}
+
+func (t *Table) UnmarshalCBOR(data []byte) error {
+	_, err := unmarshalTagged(data, (*string)(t), tagTable)
+	return err
+}
+
+// Datetime is a point in time with nanosecond precision.
+type Datetime struct {
+	time.Time
+}
+
+// String formats the datetime as a SurrealQL literal, e.g. d'2024-01-02T03:04:05.123456789Z'.
+func (d Datetime) String() string {
+	return "d" + quoteString(d.Format(time.RFC3339Nano))
+}
+
+// MarshalJSON encodes the datetime as an RFC 3339 string with nanosecond precision.
+func (d Datetime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(time.RFC3339Nano))
+}
+
+// UnmarshalJSON decodes a datetime from an RFC 3339 string, optionally wrapped in d'...' like a SurrealQL literal.
+func (d *Datetime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Datetime{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid datetime: %s", data)
+	}
+	if len(s) >= 3 && s[0] == 'd' && (s[1] == '\'' || s[1] == '"') && s[len(s)-1] == s[1] {
+		s = s[2 : len(s)-1]
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return fmt.Errorf("invalid datetime: %s", err)
+	}
+	d.Time = t
+
+	return nil
+}
+
+func (d Datetime) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagCustomDatetime, []int64{d.Unix(), int64(d.Nanosecond())})
+}
+
+func (d *Datetime) UnmarshalCBOR(data []byte) error {
+	var content cbor.RawMessage
+	number, err := unmarshalTagged(data, &content, tagCustomDatetime, tagSpecDatetime)
+	if err != nil {
+		return err
+	}
+
+	if number == tagSpecDatetime {
+		var s string
+		if err := cborDecMode.Unmarshal(content, &s); err != nil {
+			return err
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("invalid datetime: %s", err)
+		}
+		d.Time = t
+		return nil
+	}
+
+	var parts []int64
+	if err := cborDecMode.Unmarshal(content, &parts); err != nil {
+		return err
+	}
+	parts = append(parts, 0, 0)
+	d.Time = time.Unix(parts[0], parts[1]).UTC()
+
+	return nil
+}
+
+// Duration is a length of time. Unlike time.Duration, it is written and parsed with SurrealQL's units, which go up to
+// weeks and years.
+type Duration time.Duration
+
+// durationUnits are the units of a SurrealQL duration, largest first.
+var durationUnits = []struct {
+	name string
+	size uint64
+}{
+	{"y", uint64(365 * 24 * time.Hour)},
+	{"w", uint64(7 * 24 * time.Hour)},
+	{"d", uint64(24 * time.Hour)},
+	{"h", uint64(time.Hour)},
+	{"m", uint64(time.Minute)},
+	{"s", uint64(time.Second)},
+	{"ms", uint64(time.Millisecond)},
+	{"µs", uint64(time.Microsecond)},
+	{"ns", uint64(time.Nanosecond)},
+}
+
+// durationUnitSize returns the size of a unit, accepting us and μ (the Greek letter) for microseconds too.
+func durationUnitSize(name string) (uint64, bool) {
+	switch name {
+	case "us", "μs":
+		name = "µs"
+	}
+	for _, unit := range durationUnits {
+		if unit.name == name {
+			return unit.size, true
+		}
+	}
+	return 0, false
+}
+
+// ParseDuration parses a SurrealQL duration, a sequence of numbers each followed by a unit, e.g. 1w2d3h or 1m30s.
+// Valid units are y, w, d, h, m, s, ms, µs (or us) and ns; a year is 365 days.
+func ParseDuration(s string) (Duration, error) {
+	input := s
+	negative := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration: %q", input)
+	}
+
+	var total uint64
+	for s != "" {
+		i := 0
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+		whole, err := strconv.ParseUint(s[:i], 10, 63)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %q", input)
+		}
+		s = s[i:]
+
+		var fraction string
+		if s != "" && s[0] == '.' {
+			i = 1
+			for i < len(s) && isDigit(s[i]) {
+				i++
+			}
+			fraction, s = s[:i], s[i:]
+		}
+
+		i = 0
+		for i < len(s) && !isDigit(s[i]) && s[i] != '.' {
+			i++
+		}
+		size, ok := durationUnitSize(s[:i])
+		if !ok {
+			return 0, fmt.Errorf("invalid duration: unknown unit %q in %q", s[:i], input)
+		}
+		s = s[i:]
+
+		if whole > (1<<63-1)/size {
+			return 0, fmt.Errorf("invalid duration: %q overflows", input)
+		}
+		value := whole * size
+		if len(fraction) > 1 {
+			f, _ := strconv.ParseFloat("0"+fraction, 64)
+			value += uint64(f * float64(size))
+		}
+		if total += value; total > 1<<63-1 {
+			return 0, fmt.Errorf("invalid duration: %q overflows", input)
+		}
+	}
+
+	if negative {
+		return -Duration(total), nil
+	}
+	return Duration(total), nil
+}
+
+// String formats the duration with SurrealQL's units, e.g. 1w2d3h, or 0ns if it is zero.
+func (d Duration) String() string {
+	if d == 0 {
+		return "0ns"
+	}
+
+	var b strings.Builder
+	remaining := uint64(d)
+	if d < 0 {
+		b.WriteByte('-')
+		remaining = uint64(-d)
+	}
+	for _, unit := range durationUnits {
+		if remaining >= unit.size {
+			b.WriteString(strconv.FormatUint(remaining/unit.size, 10))
+			b.WriteString(unit.name)
+			remaining %= unit.size
+		}
+	}
+
+	return b.String()
+}
+
+// MarshalJSON encodes the duration as a SurrealQL duration string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a duration from a SurrealQL duration string, or from a number of nanoseconds.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = 0
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	}
+
+	var nanoseconds int64
+	if err := json.Unmarshal(data, &nanoseconds); err != nil {
+		return fmt.Errorf("invalid duration: %s", data)
+	}
+	*d = Duration(nanoseconds)
+
+	return nil
+}
+
+func (d Duration) MarshalCBOR() ([]byte, error) {
+	seconds, nanoseconds := int64(d)/int64(time.Second), int64(d)%int64(time.Second)
+	switch {
+	case nanoseconds != 0:
+		return marshalTagged(tagCustomDuration, []int64{seconds, nanoseconds})
+	case seconds != 0:
+		return marshalTagged(tagCustomDuration, []int64{seconds})
+	default:
+		return marshalTagged(tagCustomDuration, []int64{})
+	}
+}
+
+func (d *Duration) UnmarshalCBOR(data []byte) error {
+	var content cbor.RawMessage
+	number, err := unmarshalTagged(data, &content, tagCustomDuration, tagStringDuration)
+	if err != nil {
+		return err
+	}
+
+	if number == tagStringDuration {
+		var s string
+		if err := cborDecMode.Unmarshal(content, &s); err != nil {
+			return err
+		}
+		*d, err = ParseDuration(s)
+		return err
+	}
+
+	var parts []int64
+	if err := cborDecMode.Unmarshal(content, &parts); err != nil {
+		return err
+	}
+	parts = append(parts, 0, 0)
+	*d = Duration(parts[0]*int64(time.Second) + parts[1])
+
+	return nil
+}
+
+// Decimal is a decimal number of arbitrary precision, kept in its textual form so that no precision is lost.
+type Decimal string
+
+// ParseDecimal checks that s is a decimal number, optionally with the dec suffix of a SurrealQL literal, which is
+// dropped.
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSuffix(s, "dec")
+	if !isDecimal(s) {
+		return "", fmt.Errorf("invalid decimal: %q", s)
+	}
+	return Decimal(s), nil
+}
+
+// isDecimal reports whether s is a base 10 number, with an optional sign, fraction and exponent.
+func isDecimal(s string) bool {
+	digits := func() bool {
+		i := 0
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+		s = s[i:]
+		return i > 0
+	}
+
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	if !digits() {
+		return false
+	}
+	if s != "" && s[0] == '.' {
+		s = s[1:]
+		if !digits() {
+			return false
+		}
+	}
+	if s != "" && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s != "" && (s[0] == '-' || s[0] == '+') {
+			s = s[1:]
+		}
+		if !digits() {
+			return false
+		}
+	}
+	return s == ""
+}
+
+// Rat returns the exact value of the decimal, for arithmetic.
+func (d Decimal) Rat() (*big.Rat, error) {
+	if !isDecimal(string(d)) {
+		return nil, fmt.Errorf("invalid decimal: %q", string(d))
+	}
+	r, _ := new(big.Rat).SetString(string(d))
+	return r, nil
+}
+
+// MarshalJSON encodes the decimal as a string, since a JSON number would be read back as a float.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(d))
+}
+
+// UnmarshalJSON decodes a decimal from a number, kept digit for digit, or from a string.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = ""
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+
+	return nil
+}
+
+func (d Decimal) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagStringDecimal, string(d))
+}
+
+func (d *Decimal) UnmarshalCBOR(data []byte) error {
+	_, err := unmarshalTagged(data, (*string)(d), tagStringDecimal)
+	return err
+}
+
+// UUID is a universally unique identifier.
+type UUID [16]byte
+
+func (u UUID) String() string {
+	var b [36]byte
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
+
+func (u UUID) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagSpecUUID, u[:])
+}
+
+func (u *UUID) UnmarshalCBOR(data []byte) error {
+	var content cbor.RawMessage
+	number, err := unmarshalTagged(data, &content, tagSpecUUID, tagStringUUID)
+	if err != nil {
+		return err
+	}
+
+	if number == tagStringUUID {
+		var s string
+		if err := cborDecMode.Unmarshal(content, &s); err != nil {
+			return err
+		}
+		*u, err = ParseUUID(s)
+		return err
+	}
+
+	var b []byte
+	if err := cborDecMode.Unmarshal(content, &b); err != nil {
+		return err
+	}
+	if len(b) != len(u) {
+		return fmt.Errorf("invalid uuid: expected %d bytes, got %d", len(u), len(b))
+	}
+	copy(u[:], b)
+
+	return nil
+}
+
+// MarshalJSON encodes the uuid as a string, e.g. 01234567-89ab-cdef-0123-456789abcdef.
+func (u UUID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+// UnmarshalJSON decodes a uuid from its string form.
+func (u *UUID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*u = UUID{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid uuid: %s", data)
+	}
+	parsed, err := ParseUUID(s)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+
+	return nil
+}
+
+// ParseUUID parses a uuid in its canonical form, e.g. 01234567-89ab-cdef-0123-456789abcdef.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("invalid uuid: %s", s)
+	}
+
+	if _, err := hex.Decode(u[:], []byte(s[0:8]+s[9:13]+s[14:18]+s[19:23]+s[24:])); err != nil {
+		return u, fmt.Errorf("invalid uuid: %s", s)
+	}
+
+	return u, nil
+}
+
+// NoneType is the type of None.
+type NoneType struct{}
+
+// None is SurrealDB's NONE, the absence of a value, as opposed to nil, which stands for NULL. JSON has no way to tell
+// the two apart, so with EncodingJSON None is sent as null, and fields left out of a result decode to nil; use
+// EncodingCBOR where the difference matters.
+var None = NoneType{}
+
+func (NoneType) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+func (n *NoneType) UnmarshalJSON(data []byte) error {
+	if string(data) != "null" {
+		return fmt.Errorf("invalid none: %s", data)
+	}
+	return nil
+}
+
+func (NoneType) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagNone, nil)
+}
+
+func (n *NoneType) UnmarshalCBOR(data []byte) error {
+	var content any
+	_, err := unmarshalTagged(data, &content, tagNone)
+	return err
+}
+
+// Range is a range of values, e.g. the ids of RecordID{Table: "article", ID: Range{...}}. A nil bound leaves that end
+// of the range open.
+type Range struct {
+	Begin *Bound
+	End   *Bound
+}
+
+// Bound is one end of a Range.
+type Bound struct {
+	Value     any
+	Inclusive bool
+}
+
+func (r Range) MarshalCBOR() ([]byte, error) {
+	return marshalTagged(tagRange, []any{r.Begin.tagged(), r.End.tagged()})
+}
+
+func (b *Bound) tagged() any {
+	if b == nil {
+		return None
+	}
+	if b.Inclusive {
+		return cbor.Tag{Number: tagBoundIncluded, Content: b.Value}
+	}
+	return cbor.Tag{Number: tagBoundExcluded, Content: b.Value}
+}
+
+func (r *Range) UnmarshalCBOR(data []byte) error {
+	var bounds []cbor.RawMessage
+	if _, err := unmarshalTagged(data, &bounds, tagRange); err != nil {
+		return err
+	}
+	if len(bounds) != 2 {
+		return fmt.Errorf("invalid range: expected 2 bounds, got %d", len(bounds))
+	}
+
+	var err error
+	if r.Begin, err = unmarshalBound(bounds[0]); err != nil {
+		return err
+	}
+	if r.End, err = unmarshalBound(bounds[1]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func unmarshalBound(data []byte) (*Bound, error) {
+	if (cborCodec{}).shape(data) == shapeNull {
+		return nil, nil
+	}
+
+	var bound Bound
+	number, err := unmarshalTagged(data, &bound.Value, tagBoundIncluded, tagBoundExcluded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range bound: %s", err)
+	}
+	bound.Inclusive = number == tagBoundIncluded
+
+	return &bound, nil
+}
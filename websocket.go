@@ -1,12 +1,14 @@
 package surreal
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gorilla/websocket"
 	gonanoid "github.com/matoous/go-nanoid"
 	"github.com/terawatthour/surreal-go/rpc"
-	"log"
+	"log/slog"
+	"math/rand"
 	"sync"
 	"time"
 )
@@ -14,8 +16,18 @@ import (
 const (
 	Alphanumeric   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	DefaultTimeout = 10 * time.Second
+
+	DefaultReconnectBackoff    = 250 * time.Millisecond
+	DefaultReconnectMaxBackoff = 30 * time.Second
+
+	DefaultPingInterval = 3 * time.Second
+	DefaultPongTimeout  = 10 * time.Second
 )
 
+// liveQueueSize is the number of live notifications read ahead of the one being dispatched. Once the queue is full,
+// further notifications are dropped until the callbacks catch up, as reading never waits for them.
+const liveQueueSize = 256
+
 type WebSocketOptions struct {
 	// DisableCompression enables compression for the websocket connection.
 	DisableCompression bool
@@ -25,6 +37,33 @@ type WebSocketOptions struct {
 
 	// ResponseTimeout is the duration to wait for a response before timing out. Defaults to 10 seconds.
 	ResponseTimeout time.Duration
+
+	// PingInterval is how often the server is pinged to tell whether it is still there. Defaults to 3 seconds.
+	PingInterval time.Duration
+
+	// PongTimeout is how long the server may stay silent past a ping before the connection is considered dead and
+	// dropped. It also bounds the time a single message may take to be written. Defaults to 10 seconds.
+	PongTimeout time.Duration
+
+	// Reconnect enables re-dialing the server after the connection is dropped. Once re-established, the namespace and
+	// database selected with Use, the session token, variables bound with Let and running live queries are restored.
+	// Live queries keep the ids and callbacks they were started with.
+	Reconnect bool
+
+	// ReconnectMaxAttempts is the number of consecutive failed dials after which reconnecting is given up and the
+	// connection is closed. Zero means unlimited.
+	ReconnectMaxAttempts int
+
+	// ReconnectBackoff is the delay before the first dial attempt, doubled after each failed one. Defaults to 250ms.
+	ReconnectBackoff time.Duration
+
+	// ReconnectMaxBackoff caps the delay between dial attempts. Defaults to 30 seconds.
+	ReconnectMaxBackoff time.Duration
+
+	// OnReconnectCallback is called once a reconnect attempt has finished. err is nil when the connection and its
+	// session were restored, otherwise it holds either the reason the session could not be fully restored or, if
+	// reconnecting was given up, the last dial error.
+	OnReconnectCallback func(err error)
 }
 
 func (o *WebSocketOptions) responseTimeout() time.Duration {
@@ -34,16 +73,63 @@ func (o *WebSocketOptions) responseTimeout() time.Duration {
 	return o.ResponseTimeout
 }
 
+func (o *WebSocketOptions) pingInterval() time.Duration {
+	if o.PingInterval == 0 {
+		return DefaultPingInterval
+	}
+	return o.PingInterval
+}
+
+func (o *WebSocketOptions) pongTimeout() time.Duration {
+	if o.PongTimeout == 0 {
+		return DefaultPongTimeout
+	}
+	return o.PongTimeout
+}
+
+func (o *WebSocketOptions) reconnectBackoff() time.Duration {
+	if o.ReconnectBackoff == 0 {
+		return DefaultReconnectBackoff
+	}
+	return o.ReconnectBackoff
+}
+
+func (o *WebSocketOptions) reconnectMaxBackoff() time.Duration {
+	if o.ReconnectMaxBackoff == 0 {
+		return DefaultReconnectMaxBackoff
+	}
+	return o.ReconnectMaxBackoff
+}
+
 type WebSocketConnection struct {
+	url     string
 	options *Options
+	codec   codec
+	logger  *slog.Logger
 
 	conn     *websocket.Conn
 	connLock sync.Mutex
 
+	// writes hands messages over to the goroutine writing the current socket. Replaced whenever the socket is.
+	writes chan *writeRequest
+
+	// ready is closed once the current socket accepts calls, dropped is closed once it has failed. Both are replaced
+	// whenever the socket is.
+	ready   chan struct{}
+	dropped chan struct{}
+
 	responseChannels     map[string]chan rpc.Incoming
 	responseChannelsLock sync.RWMutex
 
 	liveCallbacks map[string]func(notification rpc.LiveNotification)
+	// liveRoutes maps the ids the server sends live notifications with onto the ids callbacks were registered with
+	liveRoutes map[string]string
+	liveLock   sync.RWMutex
+
+	// notifications queues live notifications for the goroutine invoking the callbacks
+	notifications chan rpc.LiveNotification
+
+	session *session
 
 	done     chan struct{}
 	doneOnce sync.Once
@@ -54,32 +140,185 @@ func establishWebsocketConnection(url string, options *Options) (Connection, err
 		options = &Options{}
 	}
 
-	dialer := websocket.DefaultDialer
-	dialer.EnableCompression = !options.WebSocketOptions.DisableCompression
-	dialer.HandshakeTimeout = 10 * time.Second
-
-	c, _, err := dialer.Dial(url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to websocket: %s", err)
-	}
+	ready := make(chan struct{})
+	close(ready)
 
 	conn := &WebSocketConnection{
-		conn:             c,
+		url:              url,
 		options:          options,
+		codec:            options.Encoding.codec(),
+		logger:           options.logger().With("url", url),
+		ready:            ready,
+		dropped:          make(chan struct{}),
 		done:             make(chan struct{}),
 		liveCallbacks:    make(map[string]func(notification rpc.LiveNotification)),
+		liveRoutes:       make(map[string]string),
+		notifications:    make(chan rpc.LiveNotification, liveQueueSize),
 		responseChannels: make(map[string]chan rpc.Incoming),
+		session:          newSession(options.Encoding.codec()),
+	}
+
+	c, err := conn.dial()
+	if err != nil {
+		return nil, err
 	}
+	conn.conn = c
+	conn.writes = make(chan *writeRequest)
+	conn.logger.Info("connected to websocket")
 
 	return conn, nil
 }
 
+func (ws *WebSocketConnection) dial() (*websocket.Conn, error) {
+	dialer := *websocket.DefaultDialer
+	dialer.EnableCompression = !ws.options.WebSocketOptions.DisableCompression
+	dialer.HandshakeTimeout = 10 * time.Second
+	dialer.Subprotocols = []string{ws.codec.subprotocol()}
+
+	c, _, err := dialer.Dial(ws.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to websocket: %s", err)
+	}
+
+	// servers predating subprotocol negotiation speak JSON only
+	if protocol := c.Subprotocol(); protocol != ws.codec.subprotocol() && (protocol != "" || ws.options.Encoding != EncodingJSON) {
+		_ = c.Close()
+		return nil, fmt.Errorf("failed to connect to websocket: server does not support %s encoding", ws.options.Encoding)
+	}
+
+	return c, nil
+}
+
 // Send writes a message to the websocket connection and waits for a response.
-// Expects a JSON serializable object.
-func (ws *WebSocketConnection) Send(method string, params []any) ([]byte, error) {
+// Expects a JSON serializable object. The wait ends early, with ctx.Err(), if ctx is cancelled or its deadline passes.
+// While the connection is being re-established, the call waits for the session to be restored first.
+func (ws *WebSocketConnection) Send(ctx context.Context, method string, params []any) ([]byte, error) {
+	timeout := time.NewTimer(ws.options.WebSocketOptions.responseTimeout())
+	defer timeout.Stop()
+
+	if err := ws.awaitReady(ctx, timeout.C); err != nil {
+		return nil, err
+	}
+
+	result, err := ws.send(ctx, timeout.C, method, ws.wireParams(method, params))
+	if err != nil {
+		return nil, err
+	}
+
+	ws.record(method, params, result)
+	return result, nil
+}
+
+// pipeline writes all calls back to back, then waits for their responses. Each call is given ResponseTimeout from
+// the moment the previous one was answered.
+func (ws *WebSocketConnection) pipeline(ctx context.Context, calls []*pendingCall) {
+	timeout := time.NewTimer(ws.options.WebSocketOptions.responseTimeout())
+	defer timeout.Stop()
+
+	if err := ws.awaitReady(ctx, timeout.C); err != nil {
+		for _, call := range calls {
+			call.resolve(nil, err)
+		}
+		return
+	}
+
+	requests := make([]*pendingResponse, len(calls))
+	for i, call := range calls {
+		request, err := ws.request(ctx, call.method, ws.wireParams(call.method, call.params))
+		if err != nil {
+			call.resolve(nil, err)
+			continue
+		}
+		requests[i] = request
+	}
+
+	for i, call := range calls {
+		if requests[i] == nil {
+			continue
+		}
+
+		timeout := time.NewTimer(ws.options.WebSocketOptions.responseTimeout())
+		result, err := ws.await(ctx, timeout.C, requests[i])
+		timeout.Stop()
+		if err == nil {
+			ws.record(call.method, call.params, result)
+		}
+		call.resolve(result, err)
+	}
+}
+
+// awaitReady waits until the socket accepts calls, which it does right away unless it is being re-established.
+func (ws *WebSocketConnection) awaitReady(ctx context.Context, timeout <-chan time.Time) error {
+	for {
+		ws.connLock.Lock()
+		ready := ws.ready
+		ws.connLock.Unlock()
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			return ErrTimeout
+		case <-ws.done:
+			return ErrClosed
+		}
+
+		// the socket may have dropped again while its session was being restored, in which case the call waits for
+		// the next one instead
+		ws.connLock.Lock()
+		current := ws.ready == ready
+		ws.connLock.Unlock()
+		if current {
+			return nil
+		}
+	}
+}
+
+// wireParams translates the live query id passed to kill into the one the server currently knows the query by.
+func (ws *WebSocketConnection) wireParams(method string, params []any) []any {
+	if method == "kill" && len(params) == 1 {
+		if id, ok := params[0].(string); ok {
+			return []any{ws.session.serverLiveID(id)}
+		}
+	}
+	return params
+}
+
+// record updates the session after a call has succeeded.
+func (ws *WebSocketConnection) record(method string, params []any, result []byte) {
+	ws.session.record(method, params, result)
+	if method == "kill" && len(params) == 1 {
+		ws.unregisterLiveCallback(fmt.Sprintf("%v", params[0]))
+	}
+}
+
+// pendingResponse is a call written to the socket whose response has not been received yet.
+type pendingResponse struct {
+	id      string
+	method  string
+	sent    time.Time
+	ch      chan rpc.Incoming
+	dropped chan struct{}
+}
+
+func (ws *WebSocketConnection) send(ctx context.Context, timeout <-chan time.Time, method string, params []any) ([]byte, error) {
+	request, err := ws.request(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+	return ws.await(ctx, timeout, request)
+}
+
+// request writes a call to the socket without waiting for its response, which must then be collected with await.
+func (ws *WebSocketConnection) request(ctx context.Context, method string, params []any) (*pendingResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	select {
 	case <-ws.done:
-		return nil, fmt.Errorf("connection is closed")
+		return nil, ErrClosed
 	default:
 	}
 
@@ -87,91 +326,422 @@ func (ws *WebSocketConnection) Send(method string, params []any) ([]byte, error)
 	outgoing := &rpc.Outgoing{
 		ID:     eventId,
 		Method: method,
-		Params: params,
+		Params: encodeParams(params),
 	}
 
 	ch := ws.openResponseChannel(eventId)
-	defer ws.removeResponseChannel(eventId)
 
-	if err := ws.write(outgoing); err != nil {
-		return nil, fmt.Errorf("failed to write message to websocket: %s", err)
+	dropped, err := ws.write(ctx, outgoing)
+	if err != nil {
+		ws.removeResponseChannel(eventId)
+		return nil, fmt.Errorf("failed to write message to websocket: %w", wrapNetError(err))
 	}
 
-	timeout := time.After(ws.options.WebSocketOptions.responseTimeout())
+	return &pendingResponse{id: eventId, method: method, sent: time.Now(), ch: ch, dropped: dropped}, nil
+}
+
+func (ws *WebSocketConnection) await(ctx context.Context, timeout <-chan time.Time, request *pendingResponse) ([]byte, error) {
+	defer ws.removeResponseChannel(request.id)
+	defer func() {
+		if threshold := ws.options.SlowCallThreshold; threshold > 0 {
+			if elapsed := time.Since(request.sent); elapsed >= threshold {
+				ws.logger.Warn("slow call", "id", request.id, "method", request.method, "duration", elapsed)
+			}
+		}
+	}()
+
 	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	case <-timeout:
-		return nil, fmt.Errorf("request timed out")
+		return nil, ErrTimeout
 	case <-ws.done:
-		return nil, fmt.Errorf("connection dropped before response was received")
-	case res, open := <-ch:
+		return nil, fmt.Errorf("%w: dropped before response was received", ErrClosed)
+	case <-request.dropped:
+		return nil, fmt.Errorf("%w: dropped before response was received", ErrClosed)
+	case res, open := <-request.ch:
 		if !open {
 			return nil, fmt.Errorf("response channel closed before response was received")
 		}
 		if res.Error != nil {
-			return nil, res.Error
+			return nil, wrapRPCError(res.Error)
 		}
 		return res.Result, nil
 	}
 }
 
+// Run serves the connection until it is closed, re-establishing it after failures if Reconnect is set.
 func (ws *WebSocketConnection) Run() {
-	ticker := time.NewTicker(3 * time.Second)
-	defer ticker.Stop()
+	go ws.dispatch()
 
 	for {
+		ws.connLock.Lock()
+		conn, writes := ws.conn, ws.writes
+		ws.connLock.Unlock()
+
+		reason := ws.serve(conn, writes)
+		if reason == nil {
+			return
+		}
+
+		if !ws.options.WebSocketOptions.Reconnect {
+			_ = ws.close(reason)
+			return
+		}
+
+		if !ws.drop(conn, reason) {
+			return
+		}
+
+		conn, err := ws.redial()
+		if err != nil {
+			ws.logger.Error("gave up reconnecting to websocket", "error", err)
+
+			if callback := ws.options.WebSocketOptions.OnReconnectCallback; callback != nil && !errors.Is(err, errClosedWhileReconnecting) {
+				callback(err)
+			}
+
+			_ = ws.close(nil)
+			return
+		}
+
+		ws.connLock.Lock()
 		select {
 		case <-ws.done:
+			ws.connLock.Unlock()
+			_ = conn.Close()
 			return
-		case <-ticker.C:
-			if err := ws.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				_ = ws.close(err)
+		default:
+		}
+		ws.conn = conn
+		ws.writes = make(chan *writeRequest)
+		ws.dropped = make(chan struct{})
+		ready, dropped := ws.ready, ws.dropped
+		ws.connLock.Unlock()
+
+		ws.logger.Info("reconnected to websocket")
+
+		go ws.restore(ready, dropped)
+	}
+}
+
+// serve runs a reader and a writer on conn until either fails, returning the reason, or the connection is closed,
+// returning nil. The other one stops once conn is closed, which is left to the caller.
+func (ws *WebSocketConnection) serve(conn *websocket.Conn, writes <-chan *writeRequest) error {
+	failed := make(chan error, 2)
+	stop := make(chan struct{})
+	defer close(stop)
 
-				if ws.options.Verbose {
-					log.Println("failed to send ping to websocket: ", err)
-				}
-				return
+	go func() { failed <- ws.readLoop(conn) }()
+	go func() { failed <- ws.writeLoop(conn, writes, stop) }()
+
+	select {
+	case <-ws.done:
+		return nil
+	case err := <-failed:
+		return ws.failure(err)
+	}
+}
+
+// readLoop reads messages from conn until it fails. The peer is considered dead, and the read fails, if nothing at
+// all, not even a pong, arrives within PingInterval plus PongTimeout.
+func (ws *WebSocketConnection) readLoop(conn *websocket.Conn) error {
+	options := &ws.options.WebSocketOptions
+	extend := func() error {
+		return conn.SetReadDeadline(time.Now().Add(options.pingInterval() + options.pongTimeout()))
+	}
+
+	if err := extend(); err != nil {
+		return err
+	}
+	conn.SetPongHandler(func(string) error {
+		return extend()
+	})
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			ws.logger.Warn("failed to read message from websocket", "error", err)
+			return err
+		}
+		if err := extend(); err != nil {
+			return err
+		}
+
+		var incoming rpc.Incoming
+		if err := ws.codec.unmarshal(msg, &incoming); err != nil {
+			ws.logger.Warn("failed to decode message from websocket", "error", err)
+			continue
+		}
+
+		ws.handleResponse(incoming)
+	}
+}
+
+// writeRequest is a message waiting to be written by the writer goroutine, which reports the outcome on result.
+type writeRequest struct {
+	messageType int
+	data        []byte
+	result      chan error
+}
+
+// writeLoop is the only goroutine writing data messages to conn. Between messages, it pings the server every
+// PingInterval. It runs until a write fails or stop is closed.
+func (ws *WebSocketConnection) writeLoop(conn *websocket.Conn, writes <-chan *writeRequest, stop <-chan struct{}) error {
+	options := &ws.options.WebSocketOptions
+
+	ticker := time.NewTicker(options.pingInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case request := <-writes:
+			err := conn.SetWriteDeadline(time.Now().Add(options.pongTimeout()))
+			if err == nil {
+				err = conn.WriteMessage(request.messageType, request.data)
 			}
-		default:
-			var incoming rpc.Incoming
-			_, msg, err := ws.conn.ReadMessage()
-			if err != nil {
-				_ = ws.close(err)
+			request.result <- err
 
-				if ws.options.Verbose {
-					log.Println("failed to read message from websocket: ", err)
-				}
-				return
+			if err != nil {
+				ws.logger.Warn("failed to write message to websocket", "error", err)
+				return err
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(options.pongTimeout())); err != nil {
+				ws.logger.Warn("failed to send ping to websocket", "error", err)
+				return err
 			}
-			if err := json.Unmarshal(msg, &incoming); err != nil {
-				if ws.options.Verbose {
-					log.Println("failed to unmarshal message from surreal: ", err)
-				}
+		}
+	}
+}
+
+// dispatch invokes live callbacks with the queued notifications, one at a time and in order, until the connection is
+// closed.
+func (ws *WebSocketConnection) dispatch() {
+	for {
+		select {
+		case <-ws.done:
+			return
+		case notification := <-ws.notifications:
+			ws.liveLock.RLock()
+			id, ok := ws.liveRoutes[notification.ID]
+			callback := ws.liveCallbacks[id]
+			ws.liveLock.RUnlock()
+
+			if !ok || callback == nil {
+				ws.logger.Debug("received notification of unknown live query", "live_id", notification.ID)
 				continue
 			}
 
-			go ws.handleResponse(incoming)
+			notification.ID = id
+			callback(notification)
 		}
 	}
 }
 
+// failure tells a genuine socket failure apart from one caused by Close.
+func (ws *WebSocketConnection) failure(err error) error {
+	select {
+	case <-ws.done:
+		return nil
+	default:
+		return err
+	}
+}
+
+// drop retires a failed socket, failing the calls still waiting on it and holding new ones back until the
+// connection is re-established. Reports false if the connection has been closed in the meantime.
+func (ws *WebSocketConnection) drop(conn *websocket.Conn, reason error) bool {
+	ws.connLock.Lock()
+
+	select {
+	case <-ws.done:
+		ws.connLock.Unlock()
+		return false
+	default:
+	}
+
+	ws.ready = make(chan struct{})
+	close(ws.dropped)
+	_ = conn.Close()
+	ws.connLock.Unlock()
+
+	ws.logger.Warn("websocket connection dropped, reconnecting", "reason", reason)
+
+	if callback := ws.options.WebSocketOptions.OnDropCallback; callback != nil {
+		callback(reason)
+	}
+
+	return true
+}
+
+var errClosedWhileReconnecting = errors.New("connection closed while reconnecting")
+
+func (ws *WebSocketConnection) redial() (*websocket.Conn, error) {
+	options := &ws.options.WebSocketOptions
+	backoff := options.reconnectBackoff()
+
+	for attempt := 1; ; attempt++ {
+		// jitter keeps many clients dropped at once from hammering the server in lockstep
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+
+		select {
+		case <-ws.done:
+			return nil, errClosedWhileReconnecting
+		case <-time.After(delay):
+		}
+
+		conn, err := ws.dial()
+		if err == nil {
+			return conn, nil
+		}
+
+		ws.logger.Warn("failed to reconnect to websocket", "attempt", attempt, "error", err)
+
+		if options.ReconnectMaxAttempts > 0 && attempt >= options.ReconnectMaxAttempts {
+			return nil, err
+		}
+
+		backoff = min(2*backoff, options.reconnectMaxBackoff())
+	}
+}
+
+// restore replays the session onto a freshly established socket, then lets the calls held back by drop through by
+// closing ready, the channel drop created for this reconnect. Should the socket drop again meanwhile, which closes
+// dropped, the replay is abandoned and drop has replaced ready, so that the calls keep waiting for the next restore.
+func (ws *WebSocketConnection) restore(ready, dropped chan struct{}) {
+	timeout := time.NewTimer(ws.options.WebSocketOptions.responseTimeout())
+	defer timeout.Stop()
+
+	ctx := context.Background()
+	snapshot := ws.session.snapshot()
+
+	var errs []error
+	replay := func(method string, params ...any) []byte {
+		// calls would otherwise go to the socket that replaced this one, which is restored on its own
+		select {
+		case <-dropped:
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", method, ErrClosed))
+			return nil
+		default:
+		}
+
+		result, err := ws.send(ctx, timeout.C, method, params)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", method, err))
+		}
+		return result
+	}
+
+	if snapshot.use != nil {
+		replay("use", snapshot.use...)
+	}
+	if snapshot.token != "" {
+		replay("authenticate", snapshot.token)
+	}
+	for identifier, value := range snapshot.vars {
+		replay("let", identifier, value)
+	}
+	for id, params := range snapshot.lives {
+		if result := replay("live", params...); result != nil {
+			serverID, err := decodeString(ws.codec, result)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to restore live query %s: %s", id, err))
+				continue
+			}
+			ws.session.remapLive(id, serverID)
+			ws.remapLiveCallback(id, serverID)
+		}
+	}
+
+	close(ready)
+
+	err := errors.Join(errs...)
+	if err != nil {
+		ws.logger.Warn("failed to fully restore session", "error", err)
+	} else {
+		ws.logger.Info("restored websocket session")
+	}
+
+	if callback := ws.options.WebSocketOptions.OnReconnectCallback; callback != nil {
+		callback(err)
+	}
+}
+
 func (ws *WebSocketConnection) RegisterLiveCallback(event string, callback func(notification rpc.LiveNotification)) {
+	ws.liveLock.Lock()
+	defer ws.liveLock.Unlock()
+
 	ws.liveCallbacks[event] = callback
+	ws.liveRoutes[event] = event
+}
+
+func (ws *WebSocketConnection) remapLiveCallback(id, serverID string) {
+	ws.liveLock.Lock()
+	defer ws.liveLock.Unlock()
+
+	for route, target := range ws.liveRoutes {
+		if target == id {
+			delete(ws.liveRoutes, route)
+		}
+	}
+	ws.liveRoutes[serverID] = id
+}
+
+func (ws *WebSocketConnection) unregisterLiveCallback(id string) {
+	ws.liveLock.Lock()
+	defer ws.liveLock.Unlock()
+
+	for route, target := range ws.liveRoutes {
+		if target == id {
+			delete(ws.liveRoutes, route)
+		}
+	}
+	delete(ws.liveCallbacks, id)
+}
+
+// closed returns a channel closed once the connection has been closed for good.
+func (ws *WebSocketConnection) closed() <-chan struct{} {
+	return ws.done
 }
 
 func (ws *WebSocketConnection) Close() error {
 	return ws.close(nil)
 }
 
-func (ws *WebSocketConnection) write(msg any) error {
-	marshalled, err := json.Marshal(msg)
+// write sends msg over the current socket, returning the channel closed once that socket fails.
+func (ws *WebSocketConnection) write(ctx context.Context, msg any) (chan struct{}, error) {
+	marshalled, err := ws.codec.marshal(msg)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	messageType := websocket.TextMessage
+	if ws.options.Encoding == EncodingCBOR {
+		messageType = websocket.BinaryMessage
 	}
 
 	ws.connLock.Lock()
-	defer ws.connLock.Unlock()
+	writes, dropped := ws.writes, ws.dropped
+	ws.connLock.Unlock()
+
+	request := &writeRequest{messageType: messageType, data: marshalled, result: make(chan error, 1)}
+
+	// a timed out write leaves the websocket in a corrupt state, so the context is only consulted until the writer
+	// has taken the message over
+	select {
+	case writes <- request:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-dropped:
+		return nil, ErrClosed
+	case <-ws.done:
+		return nil, ErrClosed
+	}
 
-	return ws.conn.WriteMessage(websocket.TextMessage, marshalled)
+	return dropped, <-request.result
 }
 
 func (ws *WebSocketConnection) close(reason error) error {
@@ -190,29 +760,63 @@ func (ws *WebSocketConnection) close(reason error) error {
 		})
 
 		ws.connLock.Unlock()
+		if reason != nil {
+			ws.logger.Warn("websocket connection dropped", "reason", reason)
+		} else {
+			ws.logger.Info("closed websocket connection")
+		}
+
 		if reason != nil && ws.options != nil && ws.options.WebSocketOptions.OnDropCallback != nil {
 			ws.options.WebSocketOptions.OnDropCallback(reason)
 		}
 	}()
 
-	_ = ws.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(1000, ""))
+	select {
+	case <-ws.dropped:
+		// the socket has already been torn down by drop, Run is busy re-dialing
+		return nil
+	default:
+	}
+
+	// control messages may be written alongside the writer goroutine
+	_ = ws.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(1000, ""), time.Now().Add(time.Second))
 	return ws.conn.Close()
 }
 
 func (ws *WebSocketConnection) handleResponse(incoming rpc.Incoming) {
 	switch incoming.ID {
 	case "", nil:
-		var event rpc.LiveNotification
-		if err := json.Unmarshal(incoming.Result, &event); err != nil {
+		event, err := decodeNotification(ws.codec, incoming.Result)
+		if err != nil {
+			ws.logger.Warn("failed to decode live notification", "error", err)
 			return
 		}
 
-		if callback, ok := ws.liveCallbacks[event.ID]; ok {
+		if callback := ws.options.OnNotificationCallback; callback != nil {
 			callback(event)
 		}
+
+		// callbacks run on the dispatcher, so that a slow one holds back other notifications but never responses, nor
+		// the pongs that keep the connection alive
+		select {
+		case ws.notifications <- event:
+		default:
+			ws.liveLock.RLock()
+			id, ok := ws.liveRoutes[event.ID]
+			ws.liveLock.RUnlock()
+			if !ok {
+				id = event.ID
+			}
+
+			ws.logger.Warn("connection dropped live notification, the queue is full", "live_id", id)
+			if callback := ws.options.OnNotificationDroppedCallback; callback != nil {
+				callback(id)
+			}
+		}
 	default:
-		ch, ok := ws.acquireResponseChannel(fmt.Sprintf("%v", incoming.ID))
+		ch, ok := ws.takeResponseChannel(fmt.Sprintf("%v", incoming.ID))
 		if !ok {
+			ws.logger.Debug("received response nobody waits for", "id", incoming.ID)
 			return
 		}
 		ch <- incoming
@@ -224,17 +828,20 @@ func (ws *WebSocketConnection) openResponseChannel(eventId string) chan rpc.Inco
 	ws.responseChannelsLock.Lock()
 	defer ws.responseChannelsLock.Unlock()
 
-	ch := make(chan rpc.Incoming)
+	// buffered, so that handing over a response never blocks the reader on a caller that has given up waiting
+	ch := make(chan rpc.Incoming, 1)
 	ws.responseChannels[eventId] = ch
 
 	return ch
 }
 
-func (ws *WebSocketConnection) acquireResponseChannel(eventId string) (chan rpc.Incoming, bool) {
-	ws.responseChannelsLock.RLock()
-	defer ws.responseChannelsLock.RUnlock()
+// takeResponseChannel removes the channel waiting for a response, so that a response is delivered at most once.
+func (ws *WebSocketConnection) takeResponseChannel(eventId string) (chan rpc.Incoming, bool) {
+	ws.responseChannelsLock.Lock()
+	defer ws.responseChannelsLock.Unlock()
 
 	ch, ok := ws.responseChannels[eventId]
+	delete(ws.responseChannels, eventId)
 	return ch, ok
 }
 